@@ -28,6 +28,11 @@ CREATE TABLE IF NOT EXISTS zones (
     id VARCHAR(21) COLLATE utf8mb4_bin NOT NULL PRIMARY KEY,
     owner VARCHAR(21) COLLATE utf8mb4_bin NOT NULL,
     name VARCHAR(255) NOT NULL UNIQUE,
+    -- secondary zones are transferred from their comma separated primaries and
+    -- stop being served at expires_at unless a refresh succeeds before
+    kind VARCHAR(16) NOT NULL DEFAULT 'primary',
+    primaries VARCHAR(1024) NOT NULL DEFAULT '',
+    expires_at TIMESTAMP NULL DEFAULT NULL,
     created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
     deleted_at TIMESTAMP NULL DEFAULT NULL,
@@ -53,4 +58,45 @@ CREATE INDEX idx_zone_entries_zone_id ON zone_entries (zone_id);
 
 CREATE INDEX idx_zone_entries_name ON zone_entries (name);
 
-CREATE UNIQUE INDEX idx_entry_name_type_rdata_hash ON zone_entries (name, type, rdata_hash);
+-- parent and child zones may both hold records for the same name (delegation NS and apex NS)
+CREATE UNIQUE INDEX idx_entry_zone_name_type_rdata_hash ON zone_entries (zone_id, name, type, rdata_hash);
+
+CREATE TABLE IF NOT EXISTS zone_transfer_acls (
+    id VARCHAR(21) COLLATE utf8mb4_bin NOT NULL PRIMARY KEY,
+    zone_id VARCHAR(21) COLLATE utf8mb4_bin NOT NULL,
+    network VARCHAR(64) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (zone_id) REFERENCES zones (id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX idx_zone_transfer_acls_zone_network ON zone_transfer_acls (zone_id, network);
+
+-- every change to a zone as the records deleted and added between two SOA serials (RFC 1995)
+CREATE TABLE IF NOT EXISTS zone_journal (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    zone_id VARCHAR(21) COLLATE utf8mb4_bin NOT NULL,
+    serial_from INT UNSIGNED NOT NULL,
+    serial_to INT UNSIGNED NOT NULL,
+    operation VARCHAR(8) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    type VARCHAR(16) NOT NULL,
+    class VARCHAR(16) NOT NULL,
+    ttl INT NOT NULL,
+    rdata TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (zone_id) REFERENCES zones (id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_zone_journal_zone_serial ON zone_journal (zone_id, serial_from);
+
+CREATE INDEX idx_zone_journal_created_at ON zone_journal (created_at);
+
+CREATE TABLE IF NOT EXISTS zone_secondaries (
+    id VARCHAR(21) COLLATE utf8mb4_bin NOT NULL PRIMARY KEY,
+    zone_id VARCHAR(21) COLLATE utf8mb4_bin NOT NULL,
+    address VARCHAR(64) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (zone_id) REFERENCES zones (id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX idx_zone_secondaries_zone_address ON zone_secondaries (zone_id, address);
@@ -0,0 +1,5 @@
+-- Upgrades a database created from an earlier base.sql. Records are unique per zone
+-- so parent and child zones may both hold the same record (delegation NS and apex NS).
+CREATE UNIQUE INDEX idx_entry_zone_name_type_rdata_hash ON zone_entries (zone_id, name, type, rdata_hash);
+
+DROP INDEX idx_entry_name_type_rdata_hash ON zone_entries;
@@ -0,0 +1,10 @@
+-- Upgrades a database created from an earlier base.sql with the per-zone transfer ACL.
+CREATE TABLE IF NOT EXISTS zone_transfer_acls (
+    id VARCHAR(21) COLLATE utf8mb4_bin NOT NULL PRIMARY KEY,
+    zone_id VARCHAR(21) COLLATE utf8mb4_bin NOT NULL,
+    network VARCHAR(64) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (zone_id) REFERENCES zones (id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX idx_zone_transfer_acls_zone_network ON zone_transfer_acls (zone_id, network);
@@ -0,0 +1,19 @@
+-- Upgrades a database created from an earlier base.sql with the zone journal used for IXFR.
+CREATE TABLE IF NOT EXISTS zone_journal (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    zone_id VARCHAR(21) COLLATE utf8mb4_bin NOT NULL,
+    serial_from INT UNSIGNED NOT NULL,
+    serial_to INT UNSIGNED NOT NULL,
+    operation VARCHAR(8) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    type VARCHAR(16) NOT NULL,
+    class VARCHAR(16) NOT NULL,
+    ttl INT NOT NULL,
+    rdata TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (zone_id) REFERENCES zones (id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_zone_journal_zone_serial ON zone_journal (zone_id, serial_from);
+
+CREATE INDEX idx_zone_journal_created_at ON zone_journal (created_at);
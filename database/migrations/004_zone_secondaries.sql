@@ -0,0 +1,10 @@
+-- Upgrades a database created from an earlier base.sql with the secondaries sent NOTIFY.
+CREATE TABLE IF NOT EXISTS zone_secondaries (
+    id VARCHAR(21) COLLATE utf8mb4_bin NOT NULL PRIMARY KEY,
+    zone_id VARCHAR(21) COLLATE utf8mb4_bin NOT NULL,
+    address VARCHAR(64) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (zone_id) REFERENCES zones (id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX idx_zone_secondaries_zone_address ON zone_secondaries (zone_id, address);
@@ -0,0 +1,6 @@
+-- Upgrades a database created from an earlier base.sql with the zone kind, existing
+-- zones become primaries.
+ALTER TABLE zones
+    ADD COLUMN kind VARCHAR(16) NOT NULL DEFAULT 'primary' AFTER name,
+    ADD COLUMN primaries VARCHAR(1024) NOT NULL DEFAULT '' AFTER kind,
+    ADD COLUMN expires_at TIMESTAMP NULL DEFAULT NULL AFTER primaries;
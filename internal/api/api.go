@@ -69,7 +69,7 @@ func StartRouter(config *config.Config) {
 	mux.HandleFunc("GET /swagger", chain.ThenFunc(middleware.SwaggerRedirect).ServeHTTP)
 	logger.Info("Swagger UI enabled", "url", fmt.Sprintf("http://%s:%d/swagger/", config.API_HOST, config.API_PORT))
 
-	handler := NewHandler(mysqlDriver, config)
+	handler := NewHandler(cacheDriver, config)
 
 	optionsPassthroughHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		logger.Info("Router: OPTIONS passthrough handler hit", "path", r.URL.Path)
@@ -110,6 +110,19 @@ func StartRouter(config *config.Config) {
 	mux.Handle("OPTIONS /api/v1/zone/{zone_id}/entry/{entry_id}", chain.Then(optionsPassthroughHandler))
 	mux.Handle("PUT /api/v1/zone/{zone_id}/entry/{entry_id}", protectedChain.ThenFunc(http.HandlerFunc(handler.UpdateZoneEntryHandler)))
 	mux.Handle("DELETE /api/v1/zone/{zone_id}/entry/{entry_id}", protectedChain.ThenFunc(http.HandlerFunc(handler.DeleteZoneEntryHandler)))
+	mux.Handle("OPTIONS /api/v1/zone/{zone_id}/soa", chain.Then(optionsPassthroughHandler))
+	mux.Handle("GET /api/v1/zone/{zone_id}/soa", protectedChain.ThenFunc(http.HandlerFunc(handler.GetZoneSOAHandler)))
+	mux.Handle("PUT /api/v1/zone/{zone_id}/soa", protectedChain.ThenFunc(http.HandlerFunc(handler.UpdateZoneSOAHandler)))
+	mux.Handle("OPTIONS /api/v1/zone/{zone_id}/transfer-acl", chain.Then(optionsPassthroughHandler))
+	mux.Handle("GET /api/v1/zone/{zone_id}/transfer-acl", protectedChain.ThenFunc(http.HandlerFunc(handler.GetZoneTransferACLHandler)))
+	mux.Handle("POST /api/v1/zone/{zone_id}/transfer-acl", protectedChain.ThenFunc(http.HandlerFunc(handler.CreateZoneTransferACLHandler)))
+	mux.Handle("OPTIONS /api/v1/zone/{zone_id}/transfer-acl/{acl_id}", chain.Then(optionsPassthroughHandler))
+	mux.Handle("DELETE /api/v1/zone/{zone_id}/transfer-acl/{acl_id}", protectedChain.ThenFunc(http.HandlerFunc(handler.DeleteZoneTransferACLHandler)))
+	mux.Handle("OPTIONS /api/v1/zone/{zone_id}/secondaries", chain.Then(optionsPassthroughHandler))
+	mux.Handle("GET /api/v1/zone/{zone_id}/secondaries", protectedChain.ThenFunc(http.HandlerFunc(handler.GetZoneSecondariesHandler)))
+	mux.Handle("POST /api/v1/zone/{zone_id}/secondaries", protectedChain.ThenFunc(http.HandlerFunc(handler.CreateZoneSecondaryHandler)))
+	mux.Handle("OPTIONS /api/v1/zone/{zone_id}/secondaries/{secondary_id}", chain.Then(optionsPassthroughHandler))
+	mux.Handle("DELETE /api/v1/zone/{zone_id}/secondaries/{secondary_id}", protectedChain.ThenFunc(http.HandlerFunc(handler.DeleteZoneSecondaryHandler)))
 
 	logger.Info("Odin DNS API running", "port", config.API_PORT)
 	http.ListenAndServe(fmt.Sprintf("%s:%d", config.API_HOST, config.API_PORT), mux)
@@ -0,0 +1,190 @@
+package api
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/Unfield/Odin-DNS/internal/models"
+	"github.com/Unfield/Odin-DNS/internal/types"
+	"github.com/Unfield/Odin-DNS/internal/util"
+	"github.com/Unfield/Odin-DNS/pkg/odintypes"
+)
+
+// recordFields are the type specific parts of a create or update entry request.
+type recordFields struct {
+	Priority *uint16
+	Weight   *uint16
+	Port     *uint16
+	Value    string
+	Values   []string
+}
+
+// buildRecordRData assembles the stored RData string from the request fields and
+// checks that the DNS server will be able to decode it. It also returns the
+// canonical name of recordType, which is what the DNS lookups search for.
+func buildRecordRData(recordType string, fields recordFields) (string, string, error) {
+	typeCode, err := odintypes.StringToType(recordType)
+	if err != nil {
+		return "", "", fmt.Errorf("unsupported record type '%s'", recordType)
+	}
+	// type 0 is reserved, OPT and 128-255 are meta types that only exist in messages (RFC 6895 3.1)
+	if typeCode == 0 || typeCode == odintypes.TYPE_OPT || (typeCode >= 128 && typeCode <= 255) {
+		return "", "", fmt.Errorf("record type '%s' can not be stored in a zone", recordType)
+	}
+
+	var rdata string
+	switch typeCode {
+	case odintypes.TYPE_MX:
+		if fields.Priority == nil {
+			return "", "", fmt.Errorf("priority missing")
+		}
+		rdata = strings.Join([]string{strconv.FormatUint(uint64(*fields.Priority), 10), fields.Value}, " ")
+	case odintypes.TYPE_SRV:
+		if fields.Priority == nil || fields.Weight == nil || fields.Port == nil {
+			return "", "", fmt.Errorf("priority, weight and port are required for SRV records")
+		}
+		rdata = fmt.Sprintf("%d %d %d %s", *fields.Priority, *fields.Weight, *fields.Port, fields.Value)
+	case odintypes.TYPE_TXT:
+		if len(fields.Values) == 0 {
+			rdata = fields.Value
+			break
+		}
+		quoted := make([]string, len(fields.Values))
+		for i, text := range fields.Values {
+			quoted[i] = odintypes.QuoteCharacterString([]byte(text))
+		}
+		rdata = strings.Join(quoted, " ")
+	default:
+		rdata = fields.Value
+	}
+
+	packed, err := util.ConvertRDataStringToBytes(typeCode, rdata)
+	if err != nil {
+		return "", "", err
+	}
+
+	if typeCode == odintypes.TYPE_TXT {
+		// store the split and quoted form so long values are chunked only once
+		rdata = odintypes.FormatTXT_RData(packed)
+	}
+
+	if typeCode == odintypes.TYPE_CAA {
+		caa, _ := odintypes.ParseCAAData(rdata)
+		if err := validateCAAProperty(caa); err != nil {
+			return "", "", err
+		}
+		// store the normalized form so the cache and the API read back the same value
+		rdata = caa.String()
+	}
+
+	return odintypes.TypeToString(typeCode), rdata, nil
+}
+
+// validateCAAProperty checks the value of the properties defined by RFC 8659.
+// Other tags are rejected since no CA would understand them anyway.
+func validateCAAProperty(caa *odintypes.CAAData) error {
+	if caa.Flags&^odintypes.CAA_FLAG_CRITICAL != 0 {
+		return fmt.Errorf("CAA flags may only be 0 or 128")
+	}
+
+	switch strings.ToLower(caa.Tag) {
+	case odintypes.CAA_TAG_ISSUE, odintypes.CAA_TAG_ISSUEWILD:
+		issuer, parameters, _ := strings.Cut(caa.Value, ";")
+		issuer = strings.TrimSpace(issuer)
+		if issuer != "" && !isValidHostname(issuer) {
+			return fmt.Errorf("invalid CAA issuer domain '%s'", issuer)
+		}
+		for _, parameter := range strings.Split(parameters, ";") {
+			parameter = strings.TrimSpace(parameter)
+			if parameter == "" {
+				continue
+			}
+			key, value, found := strings.Cut(parameter, "=")
+			if !found || key == "" || value == "" || strings.ContainsAny(key, " \t") {
+				return fmt.Errorf("invalid CAA issuer parameter '%s', expected 'key=value'", parameter)
+			}
+		}
+	case odintypes.CAA_TAG_IODEF:
+		target, err := url.Parse(caa.Value)
+		if err != nil {
+			return fmt.Errorf("invalid CAA iodef URL '%s': %w", caa.Value, err)
+		}
+		switch target.Scheme {
+		case "mailto":
+			if target.Opaque == "" {
+				return fmt.Errorf("CAA iodef mailto URL has no address")
+			}
+		case "http", "https":
+			if target.Host == "" {
+				return fmt.Errorf("CAA iodef URL has no host")
+			}
+		default:
+			return fmt.Errorf("CAA iodef URL must use mailto, http or https")
+		}
+	default:
+		return fmt.Errorf("unsupported CAA tag '%s', expected issue, issuewild or iodef", caa.Tag)
+	}
+	return nil
+}
+
+// isValidHostname reports whether name consists of letter, digit and hyphen labels.
+func isValidHostname(name string) bool {
+	name = strings.TrimSuffix(name, ".")
+	if name == "" || len(name) > 253 {
+		return false
+	}
+	for _, label := range strings.Split(name, ".") {
+		if len(label) == 0 || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+			return false
+		}
+		for _, r := range label {
+			if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-') {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// recordResponse splits the stored RData of record back into the API fields.
+func recordResponse(record types.DBRecord) (models.ZoneRecordResponse, error) {
+	response := models.ZoneRecordResponse{
+		ID:    record.ID,
+		Name:  record.Name,
+		Type:  record.Type,
+		Class: record.Class,
+		TTl:   record.TTL,
+		Value: record.RData,
+	}
+
+	switch record.Type {
+	case "MX":
+		prio, value, err := util.ConvertMXRData(record.RData)
+		if err != nil {
+			return response, fmt.Errorf("failed to parse MX value")
+		}
+		response.Priority = &prio
+		response.Value = value
+	case "SRV":
+		prio, weight, port, target, err := util.ConvertSRVRData(record.RData)
+		if err != nil {
+			return response, fmt.Errorf("failed to parse SRV value")
+		}
+		response.Priority = &prio
+		response.Weight = &weight
+		response.Port = &port
+		response.Value = target
+	case "TXT":
+		packed, err := odintypes.ParseTXT_RData(record.RData)
+		if err != nil {
+			return response, fmt.Errorf("failed to parse TXT value")
+		}
+		texts, _ := odintypes.UnpackTXTStrings(packed)
+		response.Values = texts
+		response.Value = strings.Join(texts, "")
+	}
+
+	return response, nil
+}
@@ -0,0 +1,179 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+
+	"github.com/Unfield/Odin-DNS/internal/models"
+	"github.com/Unfield/Odin-DNS/internal/types"
+	"github.com/Unfield/Odin-DNS/internal/util"
+	"github.com/go-sql-driver/mysql"
+	gonanoid "github.com/matoous/go-nanoid/v2"
+)
+
+// GetZoneSecondariesHandler lists the secondaries notified about changes to a zone
+// @Summary Get Zone Secondaries
+// @Description Returns the secondaries that are sent a NOTIFY message whenever the zone changes
+// @Tags zones
+// @Security BearerAuth
+// @Produce json
+// @Param zone_id path string true "Zone ID"
+// @Success 200 {object} models.GetZoneSecondariesResponse "Secondaries retrieved successfully"
+// @Failure 400 {object} models.GenericErrorResponse "Missing zone_id"
+// @Failure 401 {object} models.GenericErrorResponse "Unauthorized - invalid session"
+// @Failure 500 {object} models.GenericErrorResponse "Failed to get zone secondaries"
+// @Router /api/v1/zone/{zone_id}/secondaries [get]
+func (h *Handler) GetZoneSecondariesHandler(w http.ResponseWriter, r *http.Request) {
+	userSession, sessionValid := r.Context().Value("user_session").(*types.SessionContextKey)
+	if !sessionValid || userSession.Token == "" || userSession.UserID == "" {
+		util.RespondWithJSON(w, http.StatusUnauthorized, &models.GenericErrorResponse{Error: true, ErrorMessage: "Unauthorized - invalid session"})
+		return
+	}
+
+	var zoneID = r.PathValue("zone_id")
+	if zoneID == "" {
+		util.RespondWithJSON(w, http.StatusBadRequest, &models.GenericErrorResponse{Error: true, ErrorMessage: "zone_id missing"})
+		return
+	}
+
+	secondaries, err := h.store.GetZoneSecondaries(zoneID)
+	if err != nil {
+		util.RespondWithJSON(w, http.StatusInternalServerError, &models.GenericErrorResponse{Error: true, ErrorMessage: "Failed to get zone secondaries"})
+		return
+	}
+
+	response := models.GetZoneSecondariesResponse{Count: len(secondaries), Secondaries: []models.ZoneSecondaryResponse{}}
+	for _, secondary := range secondaries {
+		response.Secondaries = append(response.Secondaries, models.ZoneSecondaryResponse{Id: secondary.ID, Address: secondary.Address})
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, &response)
+}
+
+// CreateZoneSecondaryHandler adds a secondary that is notified about zone changes
+// @Summary Add Zone Secondary
+// @Description Adds a secondary that is sent a NOTIFY message whenever the zone changes. The port defaults to 53. The secondary also needs a transfer ACL entry to fetch the changes.
+// @Tags zones
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param zone_id path string true "Zone ID"
+// @Param createZoneSecondaryRequest body models.CreateZoneSecondaryRequest true "Address of the secondary"
+// @Success 200 {object} models.ZoneSecondaryResponse "Secondary added successfully"
+// @Failure 400 {object} models.GenericErrorResponse "Invalid request body, invalid address, zone not found or secondary already exists"
+// @Failure 401 {object} models.GenericErrorResponse "Unauthorized - invalid session"
+// @Failure 500 {object} models.GenericErrorResponse "Failed to create zone secondary"
+// @Router /api/v1/zone/{zone_id}/secondaries [post]
+func (h *Handler) CreateZoneSecondaryHandler(w http.ResponseWriter, r *http.Request) {
+	userSession, sessionValid := r.Context().Value("user_session").(*types.SessionContextKey)
+	if !sessionValid || userSession.Token == "" || userSession.UserID == "" {
+		util.RespondWithJSON(w, http.StatusUnauthorized, &models.GenericErrorResponse{Error: true, ErrorMessage: "Unauthorized - invalid session"})
+		return
+	}
+
+	var zoneID = r.PathValue("zone_id")
+	if zoneID == "" {
+		util.RespondWithJSON(w, http.StatusBadRequest, &models.GenericErrorResponse{Error: true, ErrorMessage: "zone_id missing"})
+		return
+	}
+
+	zone, err := h.store.GetZone(zoneID)
+	if err != nil || zone == nil {
+		util.RespondWithJSON(w, http.StatusBadRequest, &models.GenericErrorResponse{Error: true, ErrorMessage: "zone not found"})
+		return
+	}
+
+	var createRequest models.CreateZoneSecondaryRequest
+	if err := json.NewDecoder(r.Body).Decode(&createRequest); err != nil {
+		util.RespondWithJSON(w, http.StatusBadRequest, &models.GenericErrorResponse{Error: true, ErrorMessage: "Invalid request body"})
+		return
+	}
+
+	address, err := normalizeServerAddress(createRequest.Address)
+	if err != nil {
+		util.RespondWithJSON(w, http.StatusBadRequest, &models.GenericErrorResponse{Error: true, ErrorMessage: err.Error()})
+		return
+	}
+
+	secondaryID, err := gonanoid.New()
+	if err != nil {
+		util.RespondWithJSON(w, http.StatusInternalServerError, &models.GenericErrorResponse{Error: true, ErrorMessage: "Failed to create secondary id"})
+		return
+	}
+
+	secondary := types.DBZoneSecondary{
+		ID:      secondaryID,
+		ZoneID:  zone.ID,
+		Address: address,
+	}
+	if err := h.store.CreateZoneSecondary(&secondary); err != nil {
+		if mysqlErr, ok := err.(*mysql.MySQLError); ok && mysqlErr.Number == 1062 {
+			util.RespondWithJSON(w, http.StatusBadRequest, &models.GenericErrorResponse{Error: true, ErrorMessage: "Secondary is already configured for this zone"})
+			return
+		}
+		util.RespondWithJSON(w, http.StatusInternalServerError, &models.GenericErrorResponse{Error: true, ErrorMessage: "Failed to create zone secondary"})
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, &models.ZoneSecondaryResponse{Id: secondary.ID, Address: secondary.Address})
+}
+
+// DeleteZoneSecondaryHandler stops notifying a secondary about zone changes
+// @Summary Delete Zone Secondary
+// @Description Removes a secondary from the zone's NOTIFY list
+// @Tags zones
+// @Security BearerAuth
+// @Produce json
+// @Param zone_id path string true "Zone ID"
+// @Param secondary_id path string true "Secondary ID"
+// @Success 200 {object} models.DeleteZoneSecondaryResponse "Secondary deleted successfully"
+// @Failure 400 {object} models.GenericErrorResponse "Missing zone_id or secondary_id"
+// @Failure 401 {object} models.GenericErrorResponse "Unauthorized - invalid session"
+// @Failure 500 {object} models.GenericErrorResponse "Failed to delete zone secondary"
+// @Router /api/v1/zone/{zone_id}/secondaries/{secondary_id} [delete]
+func (h *Handler) DeleteZoneSecondaryHandler(w http.ResponseWriter, r *http.Request) {
+	userSession, sessionValid := r.Context().Value("user_session").(*types.SessionContextKey)
+	if !sessionValid || userSession.Token == "" || userSession.UserID == "" {
+		util.RespondWithJSON(w, http.StatusUnauthorized, &models.GenericErrorResponse{Error: true, ErrorMessage: "Unauthorized - invalid session"})
+		return
+	}
+
+	var zoneID = r.PathValue("zone_id")
+	if zoneID == "" {
+		util.RespondWithJSON(w, http.StatusBadRequest, &models.GenericErrorResponse{Error: true, ErrorMessage: "zone_id missing"})
+		return
+	}
+	var secondaryID = r.PathValue("secondary_id")
+	if secondaryID == "" {
+		util.RespondWithJSON(w, http.StatusBadRequest, &models.GenericErrorResponse{Error: true, ErrorMessage: "secondary_id missing"})
+		return
+	}
+
+	if err := h.store.DeleteZoneSecondary(zoneID, secondaryID); err != nil {
+		util.RespondWithJSON(w, http.StatusInternalServerError, &models.GenericErrorResponse{Error: true, ErrorMessage: "Failed to delete zone secondary"})
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, &models.DeleteZoneSecondaryResponse{Id: secondaryID})
+}
+
+// normalizeServerAddress turns an IP address with optional port into the
+// canonical ip:port form, using the DNS port when none is given.
+func normalizeServerAddress(address string) (string, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		host, port = address, "53"
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return "", fmt.Errorf("invalid IP address: %s", host)
+	}
+	if value, err := strconv.ParseUint(port, 10, 16); err != nil || value == 0 {
+		return "", fmt.Errorf("invalid port: %s", port)
+	}
+	return net.JoinHostPort(ip.String(), port), nil
+}
@@ -0,0 +1,204 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Unfield/Odin-DNS/internal/models"
+	"github.com/Unfield/Odin-DNS/internal/types"
+	"github.com/Unfield/Odin-DNS/internal/util"
+	"github.com/Unfield/Odin-DNS/pkg/odintypes"
+)
+
+// defaultZoneSOA builds the SOA a new zone starts with from the configured defaults.
+func (h *Handler) defaultZoneSOA(zoneName string) *odintypes.SOAData {
+	mname := h.config.SOA_MNAME
+	if mname == "" {
+		mname = "ns1." + zoneName
+	}
+	rname := h.config.SOA_RNAME
+	if rname == "" {
+		rname = "hostmaster." + zoneName
+	}
+
+	return &odintypes.SOAData{
+		MName:   strings.TrimSuffix(mname, "."),
+		RName:   normalizeSOARName(rname),
+		Serial:  odintypes.NextSOASerial(0, time.Now().UTC()),
+		Refresh: uint32(h.config.SOA_REFRESH),
+		Retry:   uint32(h.config.SOA_RETRY),
+		Expire:  uint32(h.config.SOA_EXPIRE),
+		Minimum: uint32(h.config.SOA_MINIMUM),
+	}
+}
+
+// applySOARequest overrides the SOA fields that are set in the request and returns the record TTL to use.
+func applySOARequest(soa *odintypes.SOAData, ttl uint32, request *models.ZoneSOARequest) uint32 {
+	if request == nil {
+		return ttl
+	}
+	if request.MName != "" {
+		soa.MName = strings.TrimSuffix(request.MName, ".")
+	}
+	if request.RName != "" {
+		soa.RName = normalizeSOARName(request.RName)
+	}
+	if request.Refresh != nil {
+		soa.Refresh = *request.Refresh
+	}
+	if request.Retry != nil {
+		soa.Retry = *request.Retry
+	}
+	if request.Expire != nil {
+		soa.Expire = *request.Expire
+	}
+	if request.Minimum != nil {
+		soa.Minimum = *request.Minimum
+	}
+	if request.TTL != nil {
+		ttl = *request.TTL
+	}
+	return ttl
+}
+
+// normalizeSOARName turns a mail address like hostmaster@example.com into the
+// domain name form the RNAME field uses.
+func normalizeSOARName(rname string) string {
+	return strings.TrimSuffix(strings.Replace(rname, "@", ".", 1), ".")
+}
+
+func soaResponse(record *types.DBRecord, soa *odintypes.SOAData) *models.ZoneSOAResponse {
+	return &models.ZoneSOAResponse{
+		MName:   soa.MName,
+		RName:   soa.RName,
+		Serial:  soa.Serial,
+		TTL:     record.TTL,
+		Refresh: soa.Refresh,
+		Retry:   soa.Retry,
+		Expire:  soa.Expire,
+		Minimum: soa.Minimum,
+	}
+}
+
+// GetZoneSOAHandler returns the SOA settings of a zone
+// @Summary Get Zone SOA
+// @Description Returns the SOA record of the specified zone
+// @Tags zones
+// @Security BearerAuth
+// @Produce json
+// @Param zone_id path string true "Zone ID"
+// @Success 200 {object} models.ZoneSOAResponse "SOA retrieved successfully"
+// @Failure 400 {object} models.GenericErrorResponse "Missing zone_id or zone has no SOA record"
+// @Failure 401 {object} models.GenericErrorResponse "Unauthorized - invalid session"
+// @Failure 500 {object} models.GenericErrorResponse "Failed to get SOA record"
+// @Router /api/v1/zone/{zone_id}/soa [get]
+func (h *Handler) GetZoneSOAHandler(w http.ResponseWriter, r *http.Request) {
+	userSession, sessionValid := r.Context().Value("user_session").(*types.SessionContextKey)
+	if !sessionValid || userSession.Token == "" || userSession.UserID == "" {
+		util.RespondWithJSON(w, http.StatusUnauthorized, &models.GenericErrorResponse{Error: true, ErrorMessage: "Unauthorized - invalid session"})
+		return
+	}
+
+	var zoneID = r.PathValue("zone_id")
+	if zoneID == "" {
+		util.RespondWithJSON(w, http.StatusBadRequest, &models.GenericErrorResponse{Error: true, ErrorMessage: "zone_id missing"})
+		return
+	}
+
+	record, err := h.store.GetZoneSOA(zoneID)
+	if err != nil {
+		util.RespondWithJSON(w, http.StatusInternalServerError, &models.GenericErrorResponse{Error: true, ErrorMessage: "Failed to get SOA record"})
+		return
+	}
+	if record == nil {
+		util.RespondWithJSON(w, http.StatusBadRequest, &models.GenericErrorResponse{Error: true, ErrorMessage: "zone has no SOA record"})
+		return
+	}
+
+	soa, err := odintypes.ParseSOAData(record.RData)
+	if err != nil {
+		util.RespondWithJSON(w, http.StatusInternalServerError, &models.GenericErrorResponse{Error: true, ErrorMessage: "Failed to parse SOA record"})
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, soaResponse(record, soa))
+}
+
+// UpdateZoneSOAHandler changes the SOA settings of a zone
+// @Summary Update Zone SOA
+// @Description Updates MNAME, RNAME and timers of the zone's SOA record. The serial is managed by the server and bumped on every change.
+// @Tags zones
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param zone_id path string true "Zone ID"
+// @Param updateZoneSOARequest body models.ZoneSOARequest true "SOA fields to change"
+// @Success 200 {object} models.ZoneSOAResponse "SOA updated successfully"
+// @Failure 400 {object} models.GenericErrorResponse "Invalid request body, missing zone_id or zone has no SOA record"
+// @Failure 401 {object} models.GenericErrorResponse "Unauthorized - invalid session"
+// @Failure 500 {object} models.GenericErrorResponse "Failed to update SOA record"
+// @Router /api/v1/zone/{zone_id}/soa [put]
+func (h *Handler) UpdateZoneSOAHandler(w http.ResponseWriter, r *http.Request) {
+	userSession, sessionValid := r.Context().Value("user_session").(*types.SessionContextKey)
+	if !sessionValid || userSession.Token == "" || userSession.UserID == "" {
+		util.RespondWithJSON(w, http.StatusUnauthorized, &models.GenericErrorResponse{Error: true, ErrorMessage: "Unauthorized - invalid session"})
+		return
+	}
+
+	var zoneID = r.PathValue("zone_id")
+	if zoneID == "" {
+		util.RespondWithJSON(w, http.StatusBadRequest, &models.GenericErrorResponse{Error: true, ErrorMessage: "zone_id missing"})
+		return
+	}
+
+	zone, err := h.store.GetZone(zoneID)
+	if err != nil || zone == nil {
+		util.RespondWithJSON(w, http.StatusBadRequest, &models.GenericErrorResponse{Error: true, ErrorMessage: "zone not found"})
+		return
+	}
+	if rejectSecondaryZone(w, zone) {
+		return
+	}
+
+	var updateSOARequest models.ZoneSOARequest
+	if err := json.NewDecoder(r.Body).Decode(&updateSOARequest); err != nil {
+		util.RespondWithJSON(w, http.StatusBadRequest, &models.GenericErrorResponse{Error: true, ErrorMessage: "Invalid request body"})
+		return
+	}
+
+	record, err := h.store.GetZoneSOA(zoneID)
+	if err != nil {
+		util.RespondWithJSON(w, http.StatusInternalServerError, &models.GenericErrorResponse{Error: true, ErrorMessage: "Failed to get SOA record"})
+		return
+	}
+	if record == nil {
+		util.RespondWithJSON(w, http.StatusBadRequest, &models.GenericErrorResponse{Error: true, ErrorMessage: "zone has no SOA record"})
+		return
+	}
+
+	soa, err := odintypes.ParseSOAData(record.RData)
+	if err != nil {
+		util.RespondWithJSON(w, http.StatusInternalServerError, &models.GenericErrorResponse{Error: true, ErrorMessage: "Failed to parse SOA record"})
+		return
+	}
+
+	record.TTL = applySOARequest(soa, record.TTL, &updateSOARequest)
+	if _, err := soa.Pack(); err != nil {
+		util.RespondWithJSON(w, http.StatusBadRequest, &models.GenericErrorResponse{Error: true, ErrorMessage: err.Error()})
+		return
+	}
+	record.RData = soa.String()
+
+	bumped, err := h.store.ApplyZoneChange(zoneID, nil, []types.DBRecord{*record})
+	if err != nil {
+		util.RespondWithJSON(w, http.StatusInternalServerError, &models.GenericErrorResponse{Error: true, ErrorMessage: "Failed to update SOA record"})
+		return
+	}
+	if updated, err := odintypes.ParseSOAData(bumped.RData); err == nil {
+		soa = updated
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, soaResponse(bumped, soa))
+}
@@ -0,0 +1,164 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/Unfield/Odin-DNS/internal/models"
+	"github.com/Unfield/Odin-DNS/internal/types"
+	"github.com/Unfield/Odin-DNS/internal/util"
+	"github.com/go-sql-driver/mysql"
+	gonanoid "github.com/matoous/go-nanoid/v2"
+)
+
+// GetZoneTransferACLHandler lists the secondaries allowed to transfer a zone
+// @Summary Get Zone Transfer ACL
+// @Description Returns the IP addresses and CIDR ranges that may transfer the zone via AXFR or IXFR. An empty list denies all transfers.
+// @Tags zones
+// @Security BearerAuth
+// @Produce json
+// @Param zone_id path string true "Zone ID"
+// @Success 200 {object} models.GetZoneTransferACLResponse "ACL retrieved successfully"
+// @Failure 400 {object} models.GenericErrorResponse "Missing zone_id or zone not found"
+// @Failure 401 {object} models.GenericErrorResponse "Unauthorized - invalid session"
+// @Failure 500 {object} models.GenericErrorResponse "Failed to get zone transfer ACL"
+// @Router /api/v1/zone/{zone_id}/transfer-acl [get]
+func (h *Handler) GetZoneTransferACLHandler(w http.ResponseWriter, r *http.Request) {
+	userSession, sessionValid := r.Context().Value("user_session").(*types.SessionContextKey)
+	if !sessionValid || userSession.Token == "" || userSession.UserID == "" {
+		util.RespondWithJSON(w, http.StatusUnauthorized, &models.GenericErrorResponse{Error: true, ErrorMessage: "Unauthorized - invalid session"})
+		return
+	}
+
+	var zoneID = r.PathValue("zone_id")
+	if zoneID == "" {
+		util.RespondWithJSON(w, http.StatusBadRequest, &models.GenericErrorResponse{Error: true, ErrorMessage: "zone_id missing"})
+		return
+	}
+
+	entries, err := h.store.GetZoneTransferACL(zoneID)
+	if err != nil {
+		util.RespondWithJSON(w, http.StatusInternalServerError, &models.GenericErrorResponse{Error: true, ErrorMessage: "Failed to get zone transfer ACL"})
+		return
+	}
+
+	response := models.GetZoneTransferACLResponse{Count: len(entries), Entries: []models.ZoneTransferACLResponse{}}
+	for _, entry := range entries {
+		response.Entries = append(response.Entries, models.ZoneTransferACLResponse{Id: entry.ID, Network: entry.Network})
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, &response)
+}
+
+// CreateZoneTransferACLHandler allows a secondary to transfer a zone
+// @Summary Add Zone Transfer ACL Entry
+// @Description Allows an IP address or CIDR range to transfer the zone via AXFR or IXFR
+// @Tags zones
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param zone_id path string true "Zone ID"
+// @Param createZoneTransferACLRequest body models.CreateZoneTransferACLRequest true "Address or range to allow"
+// @Success 200 {object} models.ZoneTransferACLResponse "ACL entry created successfully"
+// @Failure 400 {object} models.GenericErrorResponse "Invalid request body, invalid network, zone not found or entry already exists"
+// @Failure 401 {object} models.GenericErrorResponse "Unauthorized - invalid session"
+// @Failure 500 {object} models.GenericErrorResponse "Failed to create zone transfer ACL entry"
+// @Router /api/v1/zone/{zone_id}/transfer-acl [post]
+func (h *Handler) CreateZoneTransferACLHandler(w http.ResponseWriter, r *http.Request) {
+	userSession, sessionValid := r.Context().Value("user_session").(*types.SessionContextKey)
+	if !sessionValid || userSession.Token == "" || userSession.UserID == "" {
+		util.RespondWithJSON(w, http.StatusUnauthorized, &models.GenericErrorResponse{Error: true, ErrorMessage: "Unauthorized - invalid session"})
+		return
+	}
+
+	var zoneID = r.PathValue("zone_id")
+	if zoneID == "" {
+		util.RespondWithJSON(w, http.StatusBadRequest, &models.GenericErrorResponse{Error: true, ErrorMessage: "zone_id missing"})
+		return
+	}
+
+	zone, err := h.store.GetZone(zoneID)
+	if err != nil || zone == nil {
+		util.RespondWithJSON(w, http.StatusBadRequest, &models.GenericErrorResponse{Error: true, ErrorMessage: "zone not found"})
+		return
+	}
+
+	var createRequest models.CreateZoneTransferACLRequest
+	if err := json.NewDecoder(r.Body).Decode(&createRequest); err != nil {
+		util.RespondWithJSON(w, http.StatusBadRequest, &models.GenericErrorResponse{Error: true, ErrorMessage: "Invalid request body"})
+		return
+	}
+
+	// store the canonical form so the same range can not be added twice in different spellings
+	networks, err := util.ParseClientNets([]string{createRequest.Network})
+	if err != nil {
+		util.RespondWithJSON(w, http.StatusBadRequest, &models.GenericErrorResponse{Error: true, ErrorMessage: err.Error()})
+		return
+	}
+	network := networks[0].String()
+	if !strings.Contains(createRequest.Network, "/") {
+		network = networks[0].IP.String()
+	}
+
+	aclID, err := gonanoid.New()
+	if err != nil {
+		util.RespondWithJSON(w, http.StatusInternalServerError, &models.GenericErrorResponse{Error: true, ErrorMessage: "Failed to create entry id"})
+		return
+	}
+
+	entry := types.DBZoneTransferACL{
+		ID:      aclID,
+		ZoneID:  zone.ID,
+		Network: network,
+	}
+	if err := h.store.CreateZoneTransferACL(&entry); err != nil {
+		if mysqlErr, ok := err.(*mysql.MySQLError); ok && mysqlErr.Number == 1062 {
+			util.RespondWithJSON(w, http.StatusBadRequest, &models.GenericErrorResponse{Error: true, ErrorMessage: "Network is already allowed to transfer this zone"})
+			return
+		}
+		util.RespondWithJSON(w, http.StatusInternalServerError, &models.GenericErrorResponse{Error: true, ErrorMessage: "Failed to create zone transfer ACL entry"})
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, &models.ZoneTransferACLResponse{Id: entry.ID, Network: entry.Network})
+}
+
+// DeleteZoneTransferACLHandler revokes a secondary's permission to transfer a zone
+// @Summary Delete Zone Transfer ACL Entry
+// @Description Removes an address or range from the zone's transfer ACL
+// @Tags zones
+// @Security BearerAuth
+// @Produce json
+// @Param zone_id path string true "Zone ID"
+// @Param acl_id path string true "ACL entry ID"
+// @Success 200 {object} models.DeleteZoneTransferACLResponse "ACL entry deleted successfully"
+// @Failure 400 {object} models.GenericErrorResponse "Missing zone_id or acl_id"
+// @Failure 401 {object} models.GenericErrorResponse "Unauthorized - invalid session"
+// @Failure 500 {object} models.GenericErrorResponse "Failed to delete zone transfer ACL entry"
+// @Router /api/v1/zone/{zone_id}/transfer-acl/{acl_id} [delete]
+func (h *Handler) DeleteZoneTransferACLHandler(w http.ResponseWriter, r *http.Request) {
+	userSession, sessionValid := r.Context().Value("user_session").(*types.SessionContextKey)
+	if !sessionValid || userSession.Token == "" || userSession.UserID == "" {
+		util.RespondWithJSON(w, http.StatusUnauthorized, &models.GenericErrorResponse{Error: true, ErrorMessage: "Unauthorized - invalid session"})
+		return
+	}
+
+	var zoneID = r.PathValue("zone_id")
+	if zoneID == "" {
+		util.RespondWithJSON(w, http.StatusBadRequest, &models.GenericErrorResponse{Error: true, ErrorMessage: "zone_id missing"})
+		return
+	}
+	var aclID = r.PathValue("acl_id")
+	if aclID == "" {
+		util.RespondWithJSON(w, http.StatusBadRequest, &models.GenericErrorResponse{Error: true, ErrorMessage: "acl_id missing"})
+		return
+	}
+
+	if err := h.store.DeleteZoneTransferACL(zoneID, aclID); err != nil {
+		util.RespondWithJSON(w, http.StatusInternalServerError, &models.GenericErrorResponse{Error: true, ErrorMessage: "Failed to delete zone transfer ACL entry"})
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, &models.DeleteZoneTransferACLResponse{Id: aclID})
+}
@@ -3,9 +3,9 @@ package api
 import (
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
-	"strconv"
 	"strings"
 	"time"
 
@@ -45,7 +45,7 @@ func (h *Handler) GetZoneHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	util.RespondWithJSON(w, http.StatusOK, &models.GetZoneResponse{Id: zoneID, Name: zone.Name, Owner: zone.Owner})
+	util.RespondWithJSON(w, http.StatusOK, &models.GetZoneResponse{Id: zoneID, Name: zone.Name, Owner: zone.Owner, Kind: zone.Kind, Primaries: zone.PrimaryAddresses()})
 }
 
 // GetZonesHandler retrieves all zones for the authenticated user
@@ -81,6 +81,8 @@ func (h *Handler) GetZonesHandler(w http.ResponseWriter, r *http.Request) {
 		zones = append(zones, models.ZoneResponse{
 			ID:        current.ID,
 			Name:      current.Name,
+			Kind:      current.Kind,
+			Primaries: current.PrimaryAddresses(),
 			CreatedAt: current.CreatedAt,
 			DeletedAt: deletedAt,
 		})
@@ -117,6 +119,44 @@ func (h *Handler) CreateZoneHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	createZoneRequest.Name = strings.TrimSuffix(createZoneRequest.Name, ".")
+	if createZoneRequest.Name == "" {
+		util.RespondWithJSON(w, http.StatusBadRequest, &models.GenericErrorResponse{Error: true, ErrorMessage: "zone name missing"})
+		return
+	}
+
+	if createZoneRequest.Kind == "" {
+		createZoneRequest.Kind = types.ZoneKindPrimary
+	}
+	var primaries []string
+	switch createZoneRequest.Kind {
+	case types.ZoneKindPrimary:
+		if len(createZoneRequest.Primaries) > 0 {
+			util.RespondWithJSON(w, http.StatusBadRequest, &models.GenericErrorResponse{Error: true, ErrorMessage: "primaries are only allowed for secondary zones"})
+			return
+		}
+	case types.ZoneKindSecondary:
+		if len(createZoneRequest.Primaries) == 0 {
+			util.RespondWithJSON(w, http.StatusBadRequest, &models.GenericErrorResponse{Error: true, ErrorMessage: "a secondary zone needs at least one primary"})
+			return
+		}
+		if createZoneRequest.SOA != nil {
+			util.RespondWithJSON(w, http.StatusBadRequest, &models.GenericErrorResponse{Error: true, ErrorMessage: "the SOA of a secondary zone is transferred from its primaries"})
+			return
+		}
+		for _, primary := range createZoneRequest.Primaries {
+			address, err := normalizeServerAddress(primary)
+			if err != nil {
+				util.RespondWithJSON(w, http.StatusBadRequest, &models.GenericErrorResponse{Error: true, ErrorMessage: err.Error()})
+				return
+			}
+			primaries = append(primaries, address)
+		}
+	default:
+		util.RespondWithJSON(w, http.StatusBadRequest, &models.GenericErrorResponse{Error: true, ErrorMessage: "zone kind must be 'primary' or 'secondary'"})
+		return
+	}
+
 	zoneId, err := gonanoid.New()
 	if err != nil {
 		util.RespondWithJSON(w, http.StatusInternalServerError, &models.GenericErrorResponse{Error: true, ErrorMessage: "Failed to create zone id"})
@@ -126,6 +166,8 @@ func (h *Handler) CreateZoneHandler(w http.ResponseWriter, r *http.Request) {
 		ID:        zoneId,
 		Owner:     userSession.UserID,
 		Name:      createZoneRequest.Name,
+		Kind:      createZoneRequest.Kind,
+		Primaries: strings.Join(primaries, ","),
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 		DeletedAt: sql.NullTime{},
@@ -156,6 +198,45 @@ func (h *Handler) CreateZoneHandler(w http.ResponseWriter, r *http.Request) {
 				return
 			}
 		}
+		util.RespondWithJSON(w, http.StatusInternalServerError, &models.GenericErrorResponse{Error: true, ErrorMessage: "Failed to create zone"})
+		return
+	}
+
+	// the records of a secondary zone, SOA included, arrive with the first transfer
+	if zone.IsSecondary() {
+		util.RespondWithJSON(w, http.StatusOK, &models.CreateZoneResponse{Id: zone.ID})
+		return
+	}
+
+	soaId, err := gonanoid.New()
+	if err != nil {
+		util.RespondWithJSON(w, http.StatusInternalServerError, &models.GenericErrorResponse{Error: true, ErrorMessage: "Failed to create entry id"})
+		return
+	}
+
+	soa := h.defaultZoneSOA(zone.Name)
+	soaTTL := applySOARequest(soa, uint32(h.config.SOA_TTL), createZoneRequest.SOA)
+	if _, err := soa.Pack(); err != nil {
+		h.store.DeleteZone(zone.ID)
+		util.RespondWithJSON(w, http.StatusBadRequest, &models.GenericErrorResponse{Error: true, ErrorMessage: err.Error()})
+		return
+	}
+
+	soaEntry := types.DBRecord{
+		ID:     soaId,
+		ZoneID: zone.ID,
+		Name:   zone.Name,
+		Type:   "SOA",
+		Class:  "IN",
+		TTL:    soaTTL,
+		RData:  soa.String(),
+	}
+
+	if err := h.store.CreateRecord(&soaEntry); err != nil {
+		h.logger.Error("Failed to create SOA record, removing zone again", "zone_id", zone.ID, "error", err)
+		h.store.DeleteZone(zone.ID)
+		util.RespondWithJSON(w, http.StatusInternalServerError, &models.GenericErrorResponse{Error: true, ErrorMessage: "Failed to create SOA record"})
+		return
 	}
 
 	util.RespondWithJSON(w, http.StatusOK, &models.CreateZoneResponse{Id: zone.ID})
@@ -206,7 +287,7 @@ func (h *Handler) DeleteZoneHandler(w http.ResponseWriter, r *http.Request) {
 // @Success 200 {object} models.GetZoneRecordsResponse "Zone records retrieved successfully"
 // @Failure 400 {object} models.GenericErrorResponse "Missing zone_id parameter"
 // @Failure 401 {object} models.GenericErrorResponse "Unauthorized - invalid session"
-// @Failure 500 {object} models.GenericErrorResponse "Failed to get zone records or parse MX value"
+// @Failure 500 {object} models.GenericErrorResponse "Failed to get zone records or parse MX/SRV value"
 // @Router /api/v1/zone/{zone_id}/entries [get]
 func (h *Handler) GetZoneRecordsHandler(w http.ResponseWriter, r *http.Request) {
 	userSession, sessionValid := r.Context().Value("user_session").(*types.SessionContextKey)
@@ -230,28 +311,12 @@ func (h *Handler) GetZoneRecordsHandler(w http.ResponseWriter, r *http.Request)
 	var records []models.ZoneRecordResponse
 
 	for _, current := range dbZoneRecords {
-		var priority *uint16 = nil
-		var value string = ""
-		if current.Type == "MX" {
-			prio, val, err := util.ConvertMXRData(current.RData)
-			if err != nil {
-				util.RespondWithJSON(w, http.StatusInternalServerError, &models.GenericErrorResponse{Error: true, ErrorMessage: "Failed to parse MX value"})
-				return
-			}
-			priority = &prio
-			value = val
-		} else {
-			value = current.RData
+		record, err := recordResponse(current)
+		if err != nil {
+			util.RespondWithJSON(w, http.StatusInternalServerError, &models.GenericErrorResponse{Error: true, ErrorMessage: err.Error()})
+			return
 		}
-		records = append(records, models.ZoneRecordResponse{
-			ID:       current.ID,
-			Name:     current.Name,
-			Type:     current.Type,
-			Class:    current.Class,
-			TTl:      current.TTL,
-			Priority: priority,
-			Value:    value,
-		})
+		records = append(records, record)
 	}
 
 	util.RespondWithJSON(w, http.StatusOK, &models.GetZoneRecordsResponse{Count: len(records), Records: records})
@@ -267,7 +332,7 @@ func (h *Handler) GetZoneRecordsHandler(w http.ResponseWriter, r *http.Request)
 // @Param zone_id path string true "Zone ID"
 // @Param createZoneEntryRequest body models.CreateZoneEntryRequest true "DNS record details"
 // @Success 200 {object} models.CreateZoneEntryResponse "Zone record created successfully"
-// @Failure 400 {object} models.GenericErrorResponse "Invalid request body, missing zone_id, missing priority for MX record, or entry already exists"
+// @Failure 400 {object} models.GenericErrorResponse "Invalid request body, missing zone_id, missing priority for MX record, invalid record value, or entry already exists"
 // @Failure 401 {object} models.GenericErrorResponse "Unauthorized - invalid session"
 // @Failure 500 {object} models.GenericErrorResponse "Failed to create zone record"
 // @Router /api/v1/zone/{zone_id}/entries [post]
@@ -285,10 +350,13 @@ func (h *Handler) CreateZoneEntryHandler(w http.ResponseWriter, r *http.Request)
 	}
 
 	zone, err := h.store.GetZone(zoneID)
-	if err != nil {
+	if err != nil || zone == nil {
 		util.RespondWithJSON(w, http.StatusBadRequest, &models.GenericErrorResponse{Error: true, ErrorMessage: "zone not found"})
 		return
 	}
+	if rejectSecondaryZone(w, zone) {
+		return
+	}
 
 	var createZoneEntryRequest models.CreateZoneEntryRequest
 
@@ -304,8 +372,6 @@ func (h *Handler) CreateZoneEntryHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	var rdata string
-
 	createZoneEntryRequest.Name = strings.TrimSuffix(createZoneEntryRequest.Name, ".")
 
 	if !strings.HasSuffix(createZoneEntryRequest.Name, zone.Name) {
@@ -315,31 +381,57 @@ func (h *Handler) CreateZoneEntryHandler(w http.ResponseWriter, r *http.Request)
 	createZoneEntryRequest.Name = strings.TrimPrefix(createZoneEntryRequest.Name, "@")
 	createZoneEntryRequest.Name = strings.TrimPrefix(createZoneEntryRequest.Name, ".")
 
-	if createZoneEntryRequest.Type == "MX" {
-		if createZoneEntryRequest.Priority == nil {
-			util.RespondWithJSON(w, http.StatusBadRequest, &models.GenericErrorResponse{Error: true, ErrorMessage: "priority missing"})
-			return
-		}
-		prioString := strconv.FormatUint(uint64(*createZoneEntryRequest.Priority), 10)
-		rdata = strings.Join([]string{prioString, createZoneEntryRequest.Value}, " ")
-	} else {
-		rdata = createZoneEntryRequest.Value
+	if !isValidWildcardPlacement(createZoneEntryRequest.Name) {
+		util.RespondWithJSON(w, http.StatusBadRequest, &models.GenericErrorResponse{Error: true, ErrorMessage: "'*' is only allowed as the leftmost label"})
+		return
+	}
+
+	recordType, rdata, err := buildRecordRData(createZoneEntryRequest.Type, recordFields{
+		Priority: createZoneEntryRequest.Priority,
+		Weight:   createZoneEntryRequest.Weight,
+		Port:     createZoneEntryRequest.Port,
+		Value:    createZoneEntryRequest.Value,
+		Values:   createZoneEntryRequest.Values,
+	})
+	if err != nil {
+		util.RespondWithJSON(w, http.StatusBadRequest, &models.GenericErrorResponse{Error: true, ErrorMessage: err.Error()})
+		return
+	}
+
+	if recordType == "SOA" {
+		util.RespondWithJSON(w, http.StatusBadRequest, &models.GenericErrorResponse{Error: true, ErrorMessage: "SOA records are managed through the zone SOA endpoint"})
+		return
+	}
+
+	zoneEntries, err := h.store.GetZoneEntries(zoneID)
+	if err != nil {
+		util.RespondWithJSON(w, http.StatusInternalServerError, &models.GenericErrorResponse{Error: true, ErrorMessage: "Failed to get zone entries"})
+		return
+	}
+	if conflict := util.DNAMEConflict(zoneEntries, "", createZoneEntryRequest.Name, recordType); conflict != "" {
+		util.RespondWithJSON(w, http.StatusBadRequest, &models.GenericErrorResponse{Error: true, ErrorMessage: conflict})
+		return
+	}
+	if conflict := util.AliasConflict(zoneEntries, "", createZoneEntryRequest.Name, recordType); conflict != "" {
+		util.RespondWithJSON(w, http.StatusBadRequest, &models.GenericErrorResponse{Error: true, ErrorMessage: conflict})
+		return
 	}
 
 	entry := types.DBRecord{
 		ID:     zoneEntryId,
 		ZoneID: zoneID,
 		Name:   createZoneEntryRequest.Name,
-		Type:   createZoneEntryRequest.Type,
+		Type:   recordType,
 		Class:  createZoneEntryRequest.Class,
 		TTL:    createZoneEntryRequest.TTl,
 		RData:  rdata,
 	}
 
-	err = h.store.CreateRecord(&entry)
+	_, err = h.store.ApplyZoneChange(zoneID, nil, []types.DBRecord{entry})
 	if err != nil {
-		// same as with create zone...
-		if mysqlErr, ok := err.(*mysql.MySQLError); ok {
+		// same as with create zone, but the cache layer wraps the error so we have to unwrap it first
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) {
 			switch mysqlErr.Number {
 			case 1062:
 				util.RespondWithJSON(w, http.StatusBadRequest, &models.GenericErrorResponse{
@@ -355,6 +447,8 @@ func (h *Handler) CreateZoneEntryHandler(w http.ResponseWriter, r *http.Request)
 				return
 			}
 		}
+		util.RespondWithJSON(w, http.StatusInternalServerError, &models.GenericErrorResponse{Error: true, ErrorMessage: "Failed to save entry"})
+		return
 	}
 
 	util.RespondWithJSON(w, http.StatusOK, &models.CreateZoneEntryResponse{Id: entry.ID})
@@ -371,7 +465,7 @@ func (h *Handler) CreateZoneEntryHandler(w http.ResponseWriter, r *http.Request)
 // @Param entry_id path string true "Entry ID"
 // @Param updateZoneEntryRequest body models.UpdateZoneEntryRequest true "Updated DNS record details"
 // @Success 200 {object} models.UpdateZoneEntryResponse "Zone record updated successfully"
-// @Failure 400 {object} models.GenericErrorResponse "Invalid request body, missing parameters, missing priority for MX record, or entry already exists"
+// @Failure 400 {object} models.GenericErrorResponse "Invalid request body, missing parameters, missing priority for MX record, invalid record value, or entry already exists"
 // @Failure 401 {object} models.GenericErrorResponse "Unauthorized - invalid session"
 // @Failure 500 {object} models.GenericErrorResponse "Failed to update zone record"
 // @Router /api/v1/zone/{zone_id}/entry/{entry_id} [put]
@@ -395,10 +489,13 @@ func (h *Handler) UpdateZoneEntryHandler(w http.ResponseWriter, r *http.Request)
 	}
 
 	zone, err := h.store.GetZone(zoneID)
-	if err != nil {
+	if err != nil || zone == nil {
 		util.RespondWithJSON(w, http.StatusBadRequest, &models.GenericErrorResponse{Error: true, ErrorMessage: "zone not found"})
 		return
 	}
+	if rejectSecondaryZone(w, zone) {
+		return
+	}
 
 	var updateZoneEntryRequest models.UpdateZoneEntryRequest
 
@@ -408,8 +505,6 @@ func (h *Handler) UpdateZoneEntryHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	var rdata string
-
 	updateZoneEntryRequest.Name = strings.TrimSuffix(updateZoneEntryRequest.Name, ".")
 
 	if !strings.HasSuffix(updateZoneEntryRequest.Name, zone.Name) {
@@ -419,31 +514,68 @@ func (h *Handler) UpdateZoneEntryHandler(w http.ResponseWriter, r *http.Request)
 	updateZoneEntryRequest.Name = strings.TrimPrefix(updateZoneEntryRequest.Name, "@")
 	updateZoneEntryRequest.Name = strings.TrimPrefix(updateZoneEntryRequest.Name, ".")
 
-	if updateZoneEntryRequest.Type == "MX" {
-		if updateZoneEntryRequest.Priority == nil {
-			util.RespondWithJSON(w, http.StatusBadRequest, &models.GenericErrorResponse{Error: true, ErrorMessage: "priority missing"})
-			return
-		}
-		prioString := strconv.FormatUint(uint64(*updateZoneEntryRequest.Priority), 10)
-		rdata = strings.Join([]string{prioString, updateZoneEntryRequest.Value}, " ")
-	} else {
-		rdata = updateZoneEntryRequest.Value
+	if !isValidWildcardPlacement(updateZoneEntryRequest.Name) {
+		util.RespondWithJSON(w, http.StatusBadRequest, &models.GenericErrorResponse{Error: true, ErrorMessage: "'*' is only allowed as the leftmost label"})
+		return
+	}
+
+	existingEntry, err := h.store.GetRecord(entryID)
+	if err != nil || existingEntry == nil {
+		util.RespondWithJSON(w, http.StatusBadRequest, &models.GenericErrorResponse{Error: true, ErrorMessage: "record not found"})
+		return
+	}
+
+	if existingEntry.ZoneID != zoneID {
+		util.RespondWithJSON(w, http.StatusBadRequest, &models.GenericErrorResponse{Error: true, ErrorMessage: "record not part of that zone"})
+		return
+	}
+
+	recordType, rdata, err := buildRecordRData(updateZoneEntryRequest.Type, recordFields{
+		Priority: updateZoneEntryRequest.Priority,
+		Weight:   updateZoneEntryRequest.Weight,
+		Port:     updateZoneEntryRequest.Port,
+		Value:    updateZoneEntryRequest.Value,
+		Values:   updateZoneEntryRequest.Values,
+	})
+	if err != nil {
+		util.RespondWithJSON(w, http.StatusBadRequest, &models.GenericErrorResponse{Error: true, ErrorMessage: err.Error()})
+		return
+	}
+
+	if recordType == "SOA" || existingEntry.Type == "SOA" {
+		util.RespondWithJSON(w, http.StatusBadRequest, &models.GenericErrorResponse{Error: true, ErrorMessage: "SOA records are managed through the zone SOA endpoint"})
+		return
+	}
+
+	zoneEntries, err := h.store.GetZoneEntries(zoneID)
+	if err != nil {
+		util.RespondWithJSON(w, http.StatusInternalServerError, &models.GenericErrorResponse{Error: true, ErrorMessage: "Failed to get zone entries"})
+		return
+	}
+	if conflict := util.DNAMEConflict(zoneEntries, entryID, updateZoneEntryRequest.Name, recordType); conflict != "" {
+		util.RespondWithJSON(w, http.StatusBadRequest, &models.GenericErrorResponse{Error: true, ErrorMessage: conflict})
+		return
+	}
+	if conflict := util.AliasConflict(zoneEntries, entryID, updateZoneEntryRequest.Name, recordType); conflict != "" {
+		util.RespondWithJSON(w, http.StatusBadRequest, &models.GenericErrorResponse{Error: true, ErrorMessage: conflict})
+		return
 	}
 
 	entry := types.DBRecord{
 		ID:     entryID,
 		ZoneID: zoneID,
 		Name:   updateZoneEntryRequest.Name,
-		Type:   updateZoneEntryRequest.Type,
+		Type:   recordType,
 		Class:  updateZoneEntryRequest.Class,
 		TTL:    updateZoneEntryRequest.TTl,
 		RData:  rdata,
 	}
 
-	err = h.store.UpdateRecord(&entry)
+	_, err = h.store.ApplyZoneChange(zoneID, []types.DBRecord{*existingEntry}, []types.DBRecord{entry})
 	if err != nil {
-		// same as with create zone...
-		if mysqlErr, ok := err.(*mysql.MySQLError); ok {
+		// same as with create zone, but the cache layer wraps the error so we have to unwrap it first
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) {
 			switch mysqlErr.Number {
 			case 1062:
 				util.RespondWithJSON(w, http.StatusBadRequest, &models.GenericErrorResponse{
@@ -459,6 +591,8 @@ func (h *Handler) UpdateZoneEntryHandler(w http.ResponseWriter, r *http.Request)
 				return
 			}
 		}
+		util.RespondWithJSON(w, http.StatusInternalServerError, &models.GenericErrorResponse{Error: true, ErrorMessage: "Failed to save entry"})
+		return
 	}
 
 	util.RespondWithJSON(w, http.StatusOK, &models.UpdateZoneEntryResponse{Id: entry.ID})
@@ -497,8 +631,17 @@ func (h *Handler) DeleteZoneEntryHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	zone, err := h.store.GetZone(zoneID)
+	if err != nil || zone == nil {
+		util.RespondWithJSON(w, http.StatusBadRequest, &models.GenericErrorResponse{Error: true, ErrorMessage: "zone not found"})
+		return
+	}
+	if rejectSecondaryZone(w, zone) {
+		return
+	}
+
 	entry, err := h.store.GetRecord(entryID)
-	if err != nil {
+	if err != nil || entry == nil {
 		util.RespondWithJSON(w, http.StatusBadRequest, &models.GenericErrorResponse{Error: true, ErrorMessage: "record not found missing"})
 		return
 	}
@@ -508,9 +651,14 @@ func (h *Handler) DeleteZoneEntryHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	if entry.Type == "SOA" {
+		util.RespondWithJSON(w, http.StatusBadRequest, &models.GenericErrorResponse{Error: true, ErrorMessage: "the SOA record of a zone can not be deleted"})
+		return
+	}
+
 	// we would ususally check if the user has access to delete this entry but we are gonna skip it for this simple demo
 
-	err = h.store.DeleteRecord(entryID)
+	_, err = h.store.ApplyZoneChange(zoneID, []types.DBRecord{*entry}, nil)
 	if err != nil {
 		util.RespondWithJSON(w, http.StatusInternalServerError, &models.GenericErrorResponse{Error: true, ErrorMessage: "failed to delete record"})
 		return
@@ -518,3 +666,19 @@ func (h *Handler) DeleteZoneEntryHandler(w http.ResponseWriter, r *http.Request)
 
 	util.RespondWithJSON(w, http.StatusOK, &models.DeleteZoneEntryResponse{Id: entry.ID})
 }
+
+// rejectSecondaryZone answers with an error if zone is a secondary, its records
+// are only changed by transfers from the primaries.
+func rejectSecondaryZone(w http.ResponseWriter, zone *types.DBZone) bool {
+	if !zone.IsSecondary() {
+		return false
+	}
+	util.RespondWithJSON(w, http.StatusBadRequest, &models.GenericErrorResponse{Error: true, ErrorMessage: "records of a secondary zone are managed by its primaries"})
+	return true
+}
+
+// isValidWildcardPlacement checks that an asterisk only appears as the complete
+// leftmost label, which is the only place RFC 4592 treats it as a wildcard.
+func isValidWildcardPlacement(name string) bool {
+	return !strings.Contains(strings.TrimPrefix(name, "*."), "*")
+}
@@ -13,6 +13,37 @@ type Config struct {
 	DNS_HOST    string `json:"dns_host" yaml:"dns_host" xml:"dns_host"`
 	BUFFER_SIZE int    `json:"buffer_size" yaml:"buffer_size" xml:"buffer_size"`
 
+	DNS_TCP_ENABLED     bool          `json:"dns_tcp_enabled" yaml:"dns_tcp_enabled" xml:"dns_tcp_enabled"`
+	TCP_IDLE_TIMEOUT    time.Duration `json:"tcp_idle_timeout" yaml:"tcp_idle_timeout" xml:"tcp_idle_timeout"`
+	TCP_MAX_CONNECTIONS int           `json:"tcp_max_connections" yaml:"tcp_max_connections" xml:"tcp_max_connections"`
+	TCP_MAX_PIPELINED   int           `json:"tcp_max_pipelined" yaml:"tcp_max_pipelined" xml:"tcp_max_pipelined"`
+
+	EDNS_UDP_PAYLOAD_SIZE int `json:"edns_udp_payload_size" yaml:"edns_udp_payload_size" xml:"edns_udp_payload_size"`
+
+	ALIAS_UPSTREAM         string        `json:"alias_upstream" yaml:"alias_upstream" xml:"alias_upstream"`
+	ALIAS_UPSTREAM_TIMEOUT time.Duration `json:"alias_upstream_timeout" yaml:"alias_upstream_timeout" xml:"alias_upstream_timeout"`
+
+	ANY_FULL_OVER_TCP   bool     `json:"any_full_over_tcp" yaml:"any_full_over_tcp" xml:"any_full_over_tcp"`
+	ANY_ALLOWED_CLIENTS []string `json:"any_allowed_clients" yaml:"any_allowed_clients" xml:"any_allowed_clients"`
+
+	JOURNAL_RETENTION time.Duration `json:"journal_retention" yaml:"journal_retention" xml:"journal_retention"`
+
+	NOTIFY_TIMEOUT        time.Duration `json:"notify_timeout" yaml:"notify_timeout" xml:"notify_timeout"`
+	NOTIFY_RETRIES        int           `json:"notify_retries" yaml:"notify_retries" xml:"notify_retries"`
+	NOTIFY_RETRY_INTERVAL time.Duration `json:"notify_retry_interval" yaml:"notify_retry_interval" xml:"notify_retry_interval"`
+
+	TRANSFER_TIMEOUT time.Duration `json:"transfer_timeout" yaml:"transfer_timeout" xml:"transfer_timeout"`
+
+	UPDATE_ALLOWED_CLIENTS []string `json:"update_allowed_clients" yaml:"update_allowed_clients" xml:"update_allowed_clients"`
+
+	SOA_MNAME   string `json:"soa_mname" yaml:"soa_mname" xml:"soa_mname"`
+	SOA_RNAME   string `json:"soa_rname" yaml:"soa_rname" xml:"soa_rname"`
+	SOA_TTL     int    `json:"soa_ttl" yaml:"soa_ttl" xml:"soa_ttl"`
+	SOA_REFRESH int    `json:"soa_refresh" yaml:"soa_refresh" xml:"soa_refresh"`
+	SOA_RETRY   int    `json:"soa_retry" yaml:"soa_retry" xml:"soa_retry"`
+	SOA_EXPIRE  int    `json:"soa_expire" yaml:"soa_expire" xml:"soa_expire"`
+	SOA_MINIMUM int    `json:"soa_minimum" yaml:"soa_minimum" xml:"soa_minimum"`
+
 	API_ENABLED bool   `json:"api_enabled" yaml:"api_enabled" xml:"api_enabled"`
 	API_PORT    int    `json:"api_port" yaml:"api_port" xml:"api_port"`
 	API_HOST    string `json:"api_host" yaml:"api_host" xml:"api_host"`
@@ -41,6 +72,28 @@ func DefaultConfig() *Config {
 		DNS_PORT:                      53,
 		DNS_HOST:                      "127.0.0.1",
 		BUFFER_SIZE:                   512,
+		DNS_TCP_ENABLED:               true,
+		TCP_IDLE_TIMEOUT:              10 * time.Second,
+		TCP_MAX_CONNECTIONS:           256,
+		TCP_MAX_PIPELINED:             32,
+		EDNS_UDP_PAYLOAD_SIZE:         1232,
+		ALIAS_UPSTREAM:                "",
+		ALIAS_UPSTREAM_TIMEOUT:        2 * time.Second,
+		ANY_FULL_OVER_TCP:             true,
+		ANY_ALLOWED_CLIENTS:           []string{},
+		JOURNAL_RETENTION:             7 * 24 * time.Hour,
+		NOTIFY_TIMEOUT:                2 * time.Second,
+		NOTIFY_RETRIES:                5,
+		NOTIFY_RETRY_INTERVAL:         2 * time.Second,
+		TRANSFER_TIMEOUT:              10 * time.Second,
+		UPDATE_ALLOWED_CLIENTS:        []string{},
+		SOA_MNAME:                     "",
+		SOA_RNAME:                     "",
+		SOA_TTL:                       3600,
+		SOA_REFRESH:                   3600,
+		SOA_RETRY:                     900,
+		SOA_EXPIRE:                    1209600,
+		SOA_MINIMUM:                   300,
 		API_ENABLED:                   true,
 		API_PORT:                      8080,
 		API_HOST:                      "127.0.0.1",
@@ -64,7 +117,7 @@ func DefaultConfig() *Config {
 func LoadConfig() (*Config, error) {
 	cfg := DefaultConfig()
 
-	formatCorsString := func(input string) []string {
+	formatList := func(input string) []string {
 		if input == "" {
 			return []string{}
 		}
@@ -78,9 +131,9 @@ func LoadConfig() (*Config, error) {
 		return result
 	}
 
-	getCorsArray := func(envVar string, defaultValue []string) []string {
+	getList := func(envVar string, defaultValue []string) []string {
 		if value := os.Getenv(envVar); value != "" {
-			return formatCorsString(value)
+			return formatList(value)
 		}
 		return defaultValue
 	}
@@ -131,11 +184,42 @@ func LoadConfig() (*Config, error) {
 	cfg.DNS_HOST = getString("ODIN_DNS_HOST", cfg.DNS_HOST)
 	cfg.BUFFER_SIZE, err = getInt("ODIN_BUFFER_SIZE", cfg.BUFFER_SIZE)
 
+	cfg.DNS_TCP_ENABLED, err = getBool("ODIN_DNS_TCP_ENABLED", cfg.DNS_TCP_ENABLED)
+	cfg.TCP_IDLE_TIMEOUT, err = getDuration("ODIN_TCP_IDLE_TIMEOUT", cfg.TCP_IDLE_TIMEOUT)
+	cfg.TCP_MAX_CONNECTIONS, err = getInt("ODIN_TCP_MAX_CONNECTIONS", cfg.TCP_MAX_CONNECTIONS)
+	cfg.TCP_MAX_PIPELINED, err = getInt("ODIN_TCP_MAX_PIPELINED", cfg.TCP_MAX_PIPELINED)
+
+	cfg.EDNS_UDP_PAYLOAD_SIZE, err = getInt("ODIN_EDNS_UDP_PAYLOAD_SIZE", cfg.EDNS_UDP_PAYLOAD_SIZE)
+
+	cfg.ALIAS_UPSTREAM = getString("ODIN_ALIAS_UPSTREAM", cfg.ALIAS_UPSTREAM)
+	cfg.ALIAS_UPSTREAM_TIMEOUT, err = getDuration("ODIN_ALIAS_UPSTREAM_TIMEOUT", cfg.ALIAS_UPSTREAM_TIMEOUT)
+
+	cfg.ANY_FULL_OVER_TCP, err = getBool("ODIN_ANY_FULL_OVER_TCP", cfg.ANY_FULL_OVER_TCP)
+	cfg.ANY_ALLOWED_CLIENTS = getList("ODIN_ANY_ALLOWED_CLIENTS", cfg.ANY_ALLOWED_CLIENTS)
+
+	cfg.JOURNAL_RETENTION, err = getDuration("ODIN_JOURNAL_RETENTION", cfg.JOURNAL_RETENTION)
+
+	cfg.NOTIFY_TIMEOUT, err = getDuration("ODIN_NOTIFY_TIMEOUT", cfg.NOTIFY_TIMEOUT)
+	cfg.NOTIFY_RETRIES, err = getInt("ODIN_NOTIFY_RETRIES", cfg.NOTIFY_RETRIES)
+	cfg.NOTIFY_RETRY_INTERVAL, err = getDuration("ODIN_NOTIFY_RETRY_INTERVAL", cfg.NOTIFY_RETRY_INTERVAL)
+
+	cfg.TRANSFER_TIMEOUT, err = getDuration("ODIN_TRANSFER_TIMEOUT", cfg.TRANSFER_TIMEOUT)
+
+	cfg.UPDATE_ALLOWED_CLIENTS = getList("ODIN_UPDATE_ALLOWED_CLIENTS", cfg.UPDATE_ALLOWED_CLIENTS)
+
+	cfg.SOA_MNAME = getString("ODIN_SOA_MNAME", cfg.SOA_MNAME)
+	cfg.SOA_RNAME = getString("ODIN_SOA_RNAME", cfg.SOA_RNAME)
+	cfg.SOA_TTL, err = getInt("ODIN_SOA_TTL", cfg.SOA_TTL)
+	cfg.SOA_REFRESH, err = getInt("ODIN_SOA_REFRESH", cfg.SOA_REFRESH)
+	cfg.SOA_RETRY, err = getInt("ODIN_SOA_RETRY", cfg.SOA_RETRY)
+	cfg.SOA_EXPIRE, err = getInt("ODIN_SOA_EXPIRE", cfg.SOA_EXPIRE)
+	cfg.SOA_MINIMUM, err = getInt("ODIN_SOA_MINIMUM", cfg.SOA_MINIMUM)
+
 	cfg.API_ENABLED, err = getBool("ODIN_API_ENABLED", cfg.API_ENABLED)
 	cfg.API_PORT, err = getInt("ODIN_API_PORT", cfg.API_PORT)
 	cfg.API_HOST = getString("ODIN_API_HOST", cfg.API_HOST)
 
-	cfg.CORS_ORIGINS = getCorsArray("ODIN_CORS_ORIGINS", cfg.CORS_ORIGINS)
+	cfg.CORS_ORIGINS = getList("ODIN_CORS_ORIGINS", cfg.CORS_ORIGINS)
 
 	cfg.MySQL_DSN = getString("ODIN_MYSQL_DSN", cfg.MySQL_DSN)
 
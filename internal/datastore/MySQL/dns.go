@@ -1,11 +1,13 @@
 package mysql
 
 import (
-	"database/sql"
 	"fmt"
+	"strings"
 
+	"github.com/Unfield/Odin-DNS/internal/types"
 	"github.com/Unfield/Odin-DNS/internal/util"
 	"github.com/Unfield/Odin-DNS/pkg/odintypes"
+	"github.com/jmoiron/sqlx"
 )
 
 type DBRecord struct {
@@ -16,39 +18,217 @@ type DBRecord struct {
 	RData string
 }
 
-func (d *MySQLDriver) LookupRecordForDNSQuery(rname string, rtype uint16, rclass uint16) (*odintypes.DNSRecord, uint8, error) {
-	query := "SELECT name, type, class, ttl, rdata FROM zone_entries WHERE name = ? AND type = ? AND class = ?"
+func (d *MySQLDriver) LookupRecordForDNSQuery(zoneID string, rname string, rtype uint16, rclass uint16) ([]*odintypes.DNSRecord, uint8, error) {
+	query := "SELECT name, type, class, ttl, rdata FROM zone_entries WHERE zone_id = ? AND name = ? AND type = ? AND class = ?"
 
-	var dbRecord DBRecord
+	var dbRecords []DBRecord
 
 	rTypeStr := odintypes.TypeToString(rtype)
 	rClassStr := odintypes.ClassToString(rclass)
 
-	d.logger.Debug("Attempting DB Get", "name", rname, "type_str", rTypeStr, "class_str", rClassStr)
+	d.logger.Debug("Attempting DB Select", "name", rname, "type_str", rTypeStr, "class_str", rClassStr)
 
-	err := d.db.Get(&dbRecord, query, rname, rTypeStr, rClassStr)
+	err := d.db.Select(&dbRecords, query, zoneID, rname, rTypeStr, rClassStr)
+	if err != nil {
+		d.logger.Error("Failed to scan records from DB or other SQL error", "error", err, "name", rname, "type", rTypeStr, "class", rClassStr)
+		return nil, 0, fmt.Errorf("database query failed for %s (%s, %s): %w", rname, rTypeStr, rClassStr, err)
+	}
+
+	if len(dbRecords) == 0 {
+		d.logger.Debug("RRset not found in DB", "name", rname, "type", rTypeStr, "class", rClassStr)
+		return nil, 0, nil
+	}
+
+	rrset := make([]*odintypes.DNSRecord, 0, len(dbRecords))
+	for _, dbRecord := range dbRecords {
+		packedRData, convErr := util.ConvertRDataStringToBytes(rtype, dbRecord.RData)
+		if convErr != nil {
+			d.logger.Error("Failed to convert RData string to bytes", "type", dbRecord.Type, "rdata_string", dbRecord.RData, "error", convErr)
+			return nil, 0, fmt.Errorf("failed to convert RData string '%s' for type %s: %w", dbRecord.RData, dbRecord.Type, convErr)
+		}
+
+		rrset = append(rrset, &odintypes.DNSRecord{
+			Name:  dbRecord.Name,
+			Type:  rtype,
+			Class: rclass,
+			TTL:   dbRecord.TTL,
+			RData: packedRData,
+		})
+	}
+	// RFC 2181 5.2: all records of an RRset have to share one TTL
+	minTTL := rrset[0].TTL
+	for _, record := range rrset[1:] {
+		minTTL = min(minTTL, record.TTL)
+	}
+	for _, record := range rrset {
+		record.TTL = minTTL
+	}
+
+	d.logger.Debug("RRset successfully converted", "name", rname, "type", rTypeStr, "records", len(rrset))
+
+	return rrset, 0, nil
+}
 
+// LookupAllRecords returns every record owned by rname, grouped by type, as needed
+// to answer ANY queries. Each RRset gets the lowest TTL among its records.
+func (d *MySQLDriver) LookupAllRecords(zoneID string, rname string, rclass uint16) ([]*odintypes.DNSRecord, error) {
+	query := "SELECT name, type, class, ttl, rdata FROM zone_entries WHERE zone_id = ? AND name = ? AND class = ? ORDER BY type"
+
+	rClassStr := odintypes.ClassToString(rclass)
+
+	var dbRecords []DBRecord
+	if err := d.db.Select(&dbRecords, query, zoneID, rname, rClassStr); err != nil {
+		d.logger.Error("Failed to look up all records", "error", err, "name", rname, "class", rClassStr)
+		return nil, fmt.Errorf("database query failed for all records of %s (%s): %w", rname, rClassStr, err)
+	}
+
+	records := make([]*odintypes.DNSRecord, 0, len(dbRecords))
+	minTTLs := make(map[uint16]uint32)
+	for _, dbRecord := range dbRecords {
+		rtype, err := odintypes.StringToType(dbRecord.Type)
+		if err != nil {
+			return nil, err
+		}
+		packedRData, convErr := util.ConvertRDataStringToBytes(rtype, dbRecord.RData)
+		if convErr != nil {
+			d.logger.Error("Failed to convert RData string to bytes", "type", dbRecord.Type, "rdata_string", dbRecord.RData, "error", convErr)
+			return nil, fmt.Errorf("failed to convert RData string '%s' for type %s: %w", dbRecord.RData, dbRecord.Type, convErr)
+		}
+		records = append(records, &odintypes.DNSRecord{
+			Name:  dbRecord.Name,
+			Type:  rtype,
+			Class: rclass,
+			TTL:   dbRecord.TTL,
+			RData: packedRData,
+		})
+
+		if ttl, ok := minTTLs[rtype]; !ok || dbRecord.TTL < ttl {
+			minTTLs[rtype] = dbRecord.TTL
+		}
+	}
+	for _, record := range records {
+		record.TTL = minTTLs[record.Type]
+	}
+
+	return records, nil
+}
+
+// NameExists reports whether any record is owned by rname or by a name below it.
+// The second case covers empty non-terminals, which exist without owning records (RFC 8020).
+func (d *MySQLDriver) NameExists(zoneID string, rname string, rclass uint16) (bool, error) {
+	query := "SELECT EXISTS(SELECT 1 FROM zone_entries WHERE zone_id = ? AND class = ? AND (name = ? OR name LIKE ?))"
+
+	rClassStr := odintypes.ClassToString(rclass)
+
+	var exists bool
+	err := d.db.Get(&exists, query, zoneID, rClassStr, rname, "%."+escapeLikePattern(rname))
 	if err != nil {
-		if err == sql.ErrNoRows {
-			d.logger.Debug("Record not found in DB (sql.ErrNoRows)", "name", rname, "type", rTypeStr, "class", rClassStr)
-			return nil, 0, nil
+		d.logger.Error("Failed to check name existence", "error", err, "name", rname, "class", rClassStr)
+		return false, fmt.Errorf("database query failed for existence of %s (%s): %w", rname, rClassStr, err)
+	}
+
+	return exists, nil
+}
+
+// OwnsRecords reports whether any record is owned by rname itself.
+func (d *MySQLDriver) OwnsRecords(zoneID string, rname string, rclass uint16) (bool, error) {
+	query := "SELECT EXISTS(SELECT 1 FROM zone_entries WHERE zone_id = ? AND class = ? AND name = ?)"
+
+	rClassStr := odintypes.ClassToString(rclass)
+
+	var exists bool
+	if err := d.db.Get(&exists, query, zoneID, rClassStr, rname); err != nil {
+		d.logger.Error("Failed to check owner existence", "error", err, "name", rname, "class", rClassStr)
+		return false, fmt.Errorf("database query failed for records of %s (%s): %w", rname, rClassStr, err)
+	}
+
+	return exists, nil
+}
+
+func escapeLikePattern(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+// LookupOwnerNames returns the distinct owner names of a zone, which the cache
+// expands into the set of existing names NameExists is answered from.
+func (d *MySQLDriver) LookupOwnerNames(zoneID string, rclass uint16) ([]string, error) {
+	query := "SELECT DISTINCT name FROM zone_entries WHERE zone_id = ? AND class = ?"
+
+	rClassStr := odintypes.ClassToString(rclass)
+
+	var names []string
+	if err := d.db.Select(&names, query, zoneID, rClassStr); err != nil {
+		d.logger.Error("Failed to look up owner names", "error", err, "zone_id", zoneID, "class", rClassStr)
+		return nil, fmt.Errorf("database query failed for owner names of zone %s (%s): %w", zoneID, rClassStr, err)
+	}
+
+	return names, nil
+}
+
+// LookupAddressRecords fetches the A and AAAA records of several names with a single
+// query. It is used to fill the additional section with target addresses.
+func (d *MySQLDriver) LookupAddressRecords(zoneID string, rnames []string, rclass uint16) ([]*odintypes.DNSRecord, uint8, error) {
+	if len(rnames) == 0 {
+		return nil, 0, nil
+	}
+
+	rClassStr := odintypes.ClassToString(rclass)
+
+	query, args, err := sqlx.In("SELECT name, type, class, ttl, rdata FROM zone_entries WHERE zone_id = ? AND name IN (?) AND type IN ('A', 'AAAA') AND class = ?", zoneID, rnames, rClassStr)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to build address lookup query: %w", err)
+	}
+
+	var dbRecords []DBRecord
+	if err := d.db.Select(&dbRecords, d.db.Rebind(query), args...); err != nil {
+		d.logger.Error("Failed to look up address records", "error", err, "names", rnames)
+		return nil, 0, fmt.Errorf("database query failed for addresses of %v: %w", rnames, err)
+	}
+
+	records := make([]*odintypes.DNSRecord, 0, len(dbRecords))
+	for _, dbRecord := range dbRecords {
+		rtype, err := odintypes.StringToType(dbRecord.Type)
+		if err != nil {
+			return nil, 0, err
 		}
-		d.logger.Error("Failed to scan record from DB or other SQL error", "error", err, "name", rname, "type", rTypeStr, "class", rClassStr)
-		return nil, 0, fmt.Errorf("database query failed for %s (%s, %s): %w", rname, rTypeStr, rClassStr, err)
+		packedRData, convErr := util.ConvertRDataStringToBytes(rtype, dbRecord.RData)
+		if convErr != nil {
+			d.logger.Error("Failed to convert RData string to bytes", "type", dbRecord.Type, "rdata_string", dbRecord.RData, "error", convErr)
+			return nil, 0, fmt.Errorf("failed to convert RData string '%s' for type %s: %w", dbRecord.RData, dbRecord.Type, convErr)
+		}
+		records = append(records, &odintypes.DNSRecord{
+			Name:  dbRecord.Name,
+			Type:  rtype,
+			Class: rclass,
+			TTL:   dbRecord.TTL,
+			RData: packedRData,
+		})
+	}
+
+	return records, 0, nil
+}
+
+// FindZoneForName returns the zone with the longest name that name lies in or
+// at, so a delegated child zone wins over its parent. It returns nil if we do
+// not serve any zone containing name.
+func (d *MySQLDriver) FindZoneForName(name string) (*types.DBZone, error) {
+	candidates := []string{name}
+	for candidate := util.ParentDomain(name); candidate != ""; candidate = util.ParentDomain(candidate) {
+		candidates = append(candidates, candidate)
 	}
 
-	packedRData, convErr := util.ConvertRDataStringToBytes(rtype, dbRecord.RData)
-	if convErr != nil {
-		d.logger.Error("Failed to convert RData string to bytes", "type", dbRecord.Type, "rdata_string", dbRecord.RData, "error", convErr)
-		return nil, 0, fmt.Errorf("failed to convert RData string '%s' for type %s: %w", dbRecord.RData, dbRecord.Type, convErr)
+	query, args, err := sqlx.In("SELECT id, owner, name, kind, primaries, expires_at, created_at, updated_at FROM zones WHERE name IN (?) AND (deleted_at > NOW() OR deleted_at IS NULL) ORDER BY LENGTH(name) DESC LIMIT 1", candidates)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build zone lookup query: %w", err)
 	}
-	d.logger.Info("RData successfully converted", "packed_len", len(packedRData))
 
-	return &odintypes.DNSRecord{
-		Name:  dbRecord.Name,
-		Type:  rtype,
-		Class: rclass,
-		TTL:   dbRecord.TTL,
-		RData: packedRData,
-	}, 0, nil
+	var zones []types.DBZone
+	if err := d.db.Select(&zones, d.db.Rebind(query), args...); err != nil {
+		d.logger.Error("Failed to find zone for name", "error", err, "name", name)
+		return nil, fmt.Errorf("database query failed for zone of %s: %w", name, err)
+	}
+	if len(zones) == 0 {
+		return nil, nil
+	}
+	return &zones[0], nil
 }
@@ -0,0 +1,182 @@
+package mysql
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/Unfield/Odin-DNS/internal/types"
+	"github.com/Unfield/Odin-DNS/pkg/odintypes"
+)
+
+// ApplyZoneChange deletes and adds records of a zone, bumps its SOA serial and writes
+// the change to the zone journal in one transaction, so IXFR clients can follow every
+// serial (RFC 1995). Records are deleted by ID and an update is the old record deleted
+// and the new one added under the same ID. An added SOA replaces the zone's SOA, its
+// serial is always computed from the current one. The updated SOA is returned.
+func (d *MySQLDriver) ApplyZoneChange(zoneId string, deleted []types.DBRecord, added []types.DBRecord) (*types.DBRecord, error) {
+	tx, err := d.db.Beginx()
+	if err != nil {
+		d.logger.Error("Failed to begin zone change transaction", "error", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	query := "SELECT id, zone_id, name, type, class, ttl, rdata, created_at, updated_at FROM zone_entries WHERE zone_id = ? AND type = 'SOA' FOR UPDATE"
+	var current types.DBRecord
+	err = tx.Get(&current, query, zoneId)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("zone %s has no SOA record", zoneId)
+		}
+		d.logger.Error("Failed to lock SOA record", "error", err)
+		return nil, err
+	}
+
+	soa, err := odintypes.ParseSOAData(current.RData)
+	if err != nil {
+		return nil, fmt.Errorf("stored SOA record of zone %s is invalid: %w", zoneId, err)
+	}
+	serialFrom := soa.Serial
+
+	next := current
+	addedIDs := make(map[string]bool, len(added))
+	var addedRecords []types.DBRecord
+	for _, record := range added {
+		if record.Type == "SOA" {
+			if soa, err = odintypes.ParseSOAData(record.RData); err != nil {
+				return nil, fmt.Errorf("invalid SOA record for zone %s: %w", zoneId, err)
+			}
+			next.TTL = record.TTL
+			continue
+		}
+		record.ZoneID = zoneId
+		addedIDs[record.ID] = true
+		addedRecords = append(addedRecords, record)
+	}
+	soa.Serial = odintypes.NextSOASerial(serialFrom, time.Now().UTC())
+	next.RData = soa.String()
+
+	// the stored rows are journaled, not what the caller believes they hold
+	var deletedRecords []types.DBRecord
+	for _, record := range deleted {
+		if record.Type == "SOA" {
+			continue
+		}
+		var stored types.DBRecord
+		err := tx.Get(&stored, "SELECT id, zone_id, name, type, class, ttl, rdata, created_at, updated_at FROM zone_entries WHERE id = ? AND zone_id = ? FOR UPDATE", record.ID, zoneId)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return nil, fmt.Errorf("record %s not found in zone %s", record.ID, zoneId)
+			}
+			d.logger.Error("Failed to lock record", "error", err)
+			return nil, err
+		}
+		deletedRecords = append(deletedRecords, stored)
+
+		if addedIDs[record.ID] {
+			continue
+		}
+		if _, err := tx.Exec("DELETE FROM zone_entries WHERE id = ?", record.ID); err != nil {
+			d.logger.Error("Failed to delete record", "error", err)
+			return nil, err
+		}
+	}
+
+	replaced := make(map[string]bool, len(deletedRecords))
+	for _, record := range deletedRecords {
+		replaced[record.ID] = true
+	}
+	for _, record := range addedRecords {
+		if replaced[record.ID] {
+			_, err = tx.Exec("UPDATE zone_entries SET name = ?, type = ?, class = ?, ttl = ?, rdata = ?, updated_at = NOW() WHERE id = ?",
+				record.Name, record.Type, record.Class, record.TTL, record.RData, record.ID)
+		} else {
+			_, err = tx.Exec("INSERT INTO zone_entries (id, zone_id, name, type, class, ttl, rdata, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, NOW(), NOW())",
+				record.ID, zoneId, record.Name, record.Type, record.Class, record.TTL, record.RData)
+		}
+		if err != nil {
+			d.logger.Error("Failed to write record", "error", err)
+			return nil, err
+		}
+	}
+
+	_, err = tx.Exec("UPDATE zone_entries SET ttl = ?, rdata = ?, updated_at = NOW() WHERE id = ?", next.TTL, next.RData, next.ID)
+	if err != nil {
+		d.logger.Error("Failed to update SOA serial", "error", err)
+		return nil, err
+	}
+
+	// RFC 1995 4: the old SOA opens the deletions, the new one the additions
+	now := time.Now().UTC()
+	journal := make([]types.DBJournalEntry, 0, len(deletedRecords)+len(addedRecords)+2)
+	appendEntries := func(operation string, records ...types.DBRecord) {
+		for _, record := range records {
+			journal = append(journal, types.DBJournalEntry{
+				ZoneID:     zoneId,
+				SerialFrom: serialFrom,
+				SerialTo:   soa.Serial,
+				Operation:  operation,
+				Name:       record.Name,
+				Type:       record.Type,
+				Class:      record.Class,
+				TTL:        record.TTL,
+				RData:      record.RData,
+				CreatedAt:  now,
+			})
+		}
+	}
+	appendEntries(types.JournalDelete, current)
+	appendEntries(types.JournalDelete, deletedRecords...)
+	appendEntries(types.JournalAdd, next)
+	appendEntries(types.JournalAdd, addedRecords...)
+
+	_, err = tx.NamedExec("INSERT INTO zone_journal (zone_id, serial_from, serial_to, operation, name, type, class, ttl, rdata, created_at) VALUES (:zone_id, :serial_from, :serial_to, :operation, :name, :type, :class, :ttl, :rdata, :created_at)", journal)
+	if err != nil {
+		d.logger.Error("Failed to write zone journal", "error", err)
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		d.logger.Error("Failed to commit zone change transaction", "error", err)
+		return nil, err
+	}
+	return &next, nil
+}
+
+// GetZoneJournal returns the journal of a zone from the latest change that started
+// at fromSerial onwards, ordered as the changes were made. It is empty when the
+// journal does not reach back to fromSerial. All rows of a change share its
+// serials, the change starts at the first of them with the old SOA.
+func (d *MySQLDriver) GetZoneJournal(zoneId string, fromSerial uint32) ([]types.DBJournalEntry, error) {
+	var startID sql.NullInt64
+	query := `SELECT MIN(id) FROM zone_journal WHERE zone_id = ? AND serial_from = ? AND serial_to = (
+		SELECT serial_to FROM zone_journal WHERE zone_id = ? AND serial_from = ? ORDER BY id DESC LIMIT 1)`
+	err := d.db.Get(&startID, query, zoneId, fromSerial, zoneId, fromSerial)
+	if err != nil {
+		d.logger.Error("Failed to find zone journal start", "error", err)
+		return nil, err
+	}
+	if !startID.Valid {
+		return nil, nil
+	}
+
+	query = "SELECT id, zone_id, serial_from, serial_to, operation, name, type, class, ttl, rdata, created_at FROM zone_journal WHERE zone_id = ? AND id >= ? ORDER BY id"
+	var entries []types.DBJournalEntry
+	if err := d.db.Select(&entries, query, zoneId, startID.Int64); err != nil {
+		d.logger.Error("Failed to get zone journal", "error", err)
+		return nil, err
+	}
+	return entries, nil
+}
+
+// PruneZoneJournal drops journal entries written before the given time and
+// returns how many were removed.
+func (d *MySQLDriver) PruneZoneJournal(before time.Time) (int64, error) {
+	result, err := d.db.Exec("DELETE FROM zone_journal WHERE created_at < ?", before)
+	if err != nil {
+		d.logger.Error("Failed to prune zone journal", "error", err)
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,36 @@
+package mysql
+
+import (
+	"github.com/Unfield/Odin-DNS/internal/types"
+)
+
+func (d *MySQLDriver) GetZoneSecondaries(zoneId string) ([]types.DBZoneSecondary, error) {
+	query := "SELECT id, zone_id, address, created_at FROM zone_secondaries WHERE zone_id = ? ORDER BY created_at"
+	var secondaries []types.DBZoneSecondary
+	err := d.db.Select(&secondaries, query, zoneId)
+	if err != nil {
+		d.logger.Error("Failed to get zone secondaries", "error", err)
+		return nil, err
+	}
+	return secondaries, nil
+}
+
+func (d *MySQLDriver) CreateZoneSecondary(secondary *types.DBZoneSecondary) error {
+	query := "INSERT INTO zone_secondaries (id, zone_id, address, created_at) VALUES (?, ?, ?, NOW())"
+	_, err := d.db.Exec(query, secondary.ID, secondary.ZoneID, secondary.Address)
+	if err != nil {
+		d.logger.Error("Failed to create zone secondary", "error", err)
+		return err
+	}
+	return nil
+}
+
+func (d *MySQLDriver) DeleteZoneSecondary(zoneId string, id string) error {
+	query := "DELETE FROM zone_secondaries WHERE zone_id = ? AND id = ?"
+	_, err := d.db.Exec(query, zoneId, id)
+	if err != nil {
+		d.logger.Error("Failed to delete zone secondary", "error", err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package mysql
+
+import (
+	"time"
+
+	"github.com/Unfield/Odin-DNS/internal/types"
+)
+
+func (d *MySQLDriver) GetZoneTransferACL(zoneId string) ([]types.DBZoneTransferACL, error) {
+	query := "SELECT id, zone_id, network, created_at FROM zone_transfer_acls WHERE zone_id = ? ORDER BY created_at"
+	var entries []types.DBZoneTransferACL
+	err := d.db.Select(&entries, query, zoneId)
+	if err != nil {
+		d.logger.Error("Failed to get zone transfer ACL", "error", err)
+		return nil, err
+	}
+	return entries, nil
+}
+
+func (d *MySQLDriver) CreateZoneTransferACL(entry *types.DBZoneTransferACL) error {
+	query := "INSERT INTO zone_transfer_acls (id, zone_id, network, created_at) VALUES (?, ?, ?, NOW())"
+	_, err := d.db.Exec(query, entry.ID, entry.ZoneID, entry.Network)
+	if err != nil {
+		d.logger.Error("Failed to create zone transfer ACL entry", "error", err)
+		return err
+	}
+	return nil
+}
+
+func (d *MySQLDriver) DeleteZoneTransferACL(zoneId string, id string) error {
+	query := "DELETE FROM zone_transfer_acls WHERE zone_id = ? AND id = ?"
+	_, err := d.db.Exec(query, zoneId, id)
+	if err != nil {
+		d.logger.Error("Failed to delete zone transfer ACL entry", "error", err)
+		return err
+	}
+	return nil
+}
+
+func (d *MySQLDriver) GetSecondaryZones() ([]types.DBZone, error) {
+	query := "SELECT id, owner, name, kind, primaries, expires_at, created_at, updated_at FROM zones WHERE kind = ? AND (deleted_at > NOW() OR deleted_at IS NULL)"
+	var zones []types.DBZone
+	err := d.db.Select(&zones, query, types.ZoneKindSecondary)
+	if err != nil {
+		d.logger.Error("Failed to get secondary zones", "error", err)
+		return nil, err
+	}
+	return zones, nil
+}
+
+// transferInsertBatch bounds the rows per INSERT so large zones stay below the
+// placeholder limit of prepared statements.
+const transferInsertBatch = 1000
+
+// ReplaceZoneRecords swaps all records of a secondary zone for the content of a
+// full zone transfer in one transaction, so queries never see a partial zone.
+func (d *MySQLDriver) ReplaceZoneRecords(zoneId string, records []types.DBRecord) error {
+	tx, err := d.db.Beginx()
+	if err != nil {
+		d.logger.Error("Failed to begin zone replace transaction", "error", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM zone_entries WHERE zone_id = ?", zoneId); err != nil {
+		d.logger.Error("Failed to delete zone records", "error", err)
+		return err
+	}
+
+	for start := 0; start < len(records); start += transferInsertBatch {
+		batch := records[start:min(start+transferInsertBatch, len(records))]
+		_, err := tx.NamedExec("INSERT INTO zone_entries (id, zone_id, name, type, class, ttl, rdata, created_at, updated_at) VALUES (:id, :zone_id, :name, :type, :class, :ttl, :rdata, NOW(), NOW()) ON DUPLICATE KEY UPDATE ttl = VALUES(ttl)", batch)
+		if err != nil {
+			d.logger.Error("Failed to insert zone records", "error", err)
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		d.logger.Error("Failed to commit zone replace transaction", "error", err)
+		return err
+	}
+	return nil
+}
+
+// ApplyZoneDelta applies one change of an incremental zone transfer. Transferred
+// records have no IDs of ours, so deletions match on the record content.
+func (d *MySQLDriver) ApplyZoneDelta(zoneId string, deleted []types.DBRecord, added []types.DBRecord) error {
+	tx, err := d.db.Beginx()
+	if err != nil {
+		d.logger.Error("Failed to begin zone delta transaction", "error", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, record := range deleted {
+		_, err := tx.Exec("DELETE FROM zone_entries WHERE zone_id = ? AND name = ? AND type = ? AND class = ? AND rdata_hash = SHA2(?, 256)",
+			zoneId, record.Name, record.Type, record.Class, record.RData)
+		if err != nil {
+			d.logger.Error("Failed to delete transferred record", "error", err)
+			return err
+		}
+	}
+	for _, record := range added {
+		_, err := tx.Exec("INSERT INTO zone_entries (id, zone_id, name, type, class, ttl, rdata, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, NOW(), NOW()) ON DUPLICATE KEY UPDATE ttl = VALUES(ttl)",
+			record.ID, zoneId, record.Name, record.Type, record.Class, record.TTL, record.RData)
+		if err != nil {
+			d.logger.Error("Failed to insert transferred record", "error", err)
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		d.logger.Error("Failed to commit zone delta transaction", "error", err)
+		return err
+	}
+	return nil
+}
+
+func (d *MySQLDriver) SetZoneExpiry(zoneId string, expiresAt time.Time) error {
+	_, err := d.db.Exec("UPDATE zones SET expires_at = ? WHERE id = ?", expiresAt, zoneId)
+	if err != nil {
+		d.logger.Error("Failed to set zone expiry", "error", err)
+		return err
+	}
+	return nil
+}
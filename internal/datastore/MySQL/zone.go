@@ -1,11 +1,13 @@
 package mysql
 
 import (
+	"database/sql"
+
 	"github.com/Unfield/Odin-DNS/internal/types"
 )
 
 func (d *MySQLDriver) GetZone(id string) (*types.DBZone, error) {
-	query := "SELECT id, owner, name, created_at, updated_at FROM zones WHERE id = ?"
+	query := "SELECT id, owner, name, kind, primaries, expires_at, created_at, updated_at FROM zones WHERE id = ?"
 	var zone types.DBZone
 	err := d.db.Get(&zone, query, id)
 	if err != nil {
@@ -20,9 +22,9 @@ func (d *MySQLDriver) GetZone(id string) (*types.DBZone, error) {
 }
 
 func (d *MySQLDriver) CreateZone(zone *types.DBZone) (err error) {
-	query := "INSERT INTO zones (id, owner, name, created_at, updated_at) VALUES (?, ?, ?, ?, ?)"
+	query := "INSERT INTO zones (id, owner, name, kind, primaries, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?)"
 
-	_, err = d.db.Exec(query, zone.ID, zone.Owner, zone.Name, zone.CreatedAt, zone.UpdatedAt)
+	_, err = d.db.Exec(query, zone.ID, zone.Owner, zone.Name, zone.Kind, zone.Primaries, zone.CreatedAt, zone.UpdatedAt)
 	if err != nil {
 		d.logger.Error("Failed to create zone", "error", err)
 		return err
@@ -31,8 +33,8 @@ func (d *MySQLDriver) CreateZone(zone *types.DBZone) (err error) {
 }
 
 func (d *MySQLDriver) UpdateZone(zone *types.DBZone) error {
-	query := "UPDATE zones SET name = ?, updated_at = ?, deleted_at = ? WHERE id = ?"
-	_, err := d.db.Exec(query, zone.Name, zone.UpdatedAt, zone.DeletedAt, zone.ID)
+	query := "UPDATE zones SET name = ?, primaries = ?, updated_at = ?, deleted_at = ? WHERE id = ?"
+	_, err := d.db.Exec(query, zone.Name, zone.Primaries, zone.UpdatedAt, zone.DeletedAt, zone.ID)
 	if err != nil {
 		d.logger.Error("Failed to update zone", "error", err)
 		return err
@@ -51,7 +53,7 @@ func (d *MySQLDriver) DeleteZone(id string) error {
 }
 
 func (d *MySQLDriver) GetFullZone(name string) (*types.DBZone, []types.DBRecord, error) {
-	query := "SELECT id, owner, name, created_at, updated_at FROM zones WHERE name = ?"
+	query := "SELECT id, owner, name, kind, primaries, expires_at, created_at, updated_at FROM zones WHERE name = ?"
 	var zone types.DBZone
 	err := d.db.Get(&zone, query, name)
 	if err != nil {
@@ -75,7 +77,7 @@ func (d *MySQLDriver) GetFullZone(name string) (*types.DBZone, []types.DBRecord,
 }
 
 func (d *MySQLDriver) GetFullZoneById(id string) (*types.DBZone, []types.DBRecord, error) {
-	query := "SELECT id, owner, name, created_at, updated_at FROM zones WHERE id = ?"
+	query := "SELECT id, owner, name, kind, primaries, expires_at, created_at, updated_at FROM zones WHERE id = ?"
 	var zone types.DBZone
 	err := d.db.Get(&zone, query, id)
 	if err != nil {
@@ -159,7 +161,7 @@ func (d *MySQLDriver) DeleteRecord(id string) error {
 }
 
 func (d *MySQLDriver) GetZones(owner string) ([]types.DBZone, error) {
-	query := "SELECT id, owner, name, created_at, updated_at, deleted_at FROM zones WHERE owner = ? AND (deleted_at > NOW() OR deleted_at IS NULL)"
+	query := "SELECT id, owner, name, kind, primaries, expires_at, created_at, updated_at, deleted_at FROM zones WHERE owner = ? AND (deleted_at > NOW() OR deleted_at IS NULL)"
 	var zones []types.DBZone
 	err := d.db.Select(&zones, query, owner)
 	if err != nil {
@@ -169,6 +171,18 @@ func (d *MySQLDriver) GetZones(owner string) ([]types.DBZone, error) {
 	return zones, nil
 }
 
+// GetAllZones returns every zone that is not deleted, of all owners.
+func (d *MySQLDriver) GetAllZones() ([]types.DBZone, error) {
+	query := "SELECT id, owner, name, kind, primaries, expires_at, created_at, updated_at FROM zones WHERE deleted_at > NOW() OR deleted_at IS NULL"
+	var zones []types.DBZone
+	err := d.db.Select(&zones, query)
+	if err != nil {
+		d.logger.Error("Failed to get all zones", "error", err)
+		return nil, err
+	}
+	return zones, nil
+}
+
 func (d *MySQLDriver) GetZoneEntries(zoneId string) ([]types.DBRecord, error) {
 	query := "SELECT id, zone_id, name, type, class, ttl, rdata, created_at, updated_at, deleted_at FROM zone_entries WHERE zone_id = ? AND (deleted_at > NOW() OR deleted_at IS NULL)"
 	var entries []types.DBRecord
@@ -179,3 +193,18 @@ func (d *MySQLDriver) GetZoneEntries(zoneId string) ([]types.DBRecord, error) {
 	}
 	return entries, nil
 }
+
+func (d *MySQLDriver) GetZoneSOA(zoneId string) (*types.DBRecord, error) {
+	query := "SELECT id, zone_id, name, type, class, ttl, rdata, created_at, updated_at FROM zone_entries WHERE zone_id = ? AND type = 'SOA'"
+	var record types.DBRecord
+	err := d.db.Get(&record, query, zoneId)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			d.logger.Info("SOA record not found", "zone_id", zoneId)
+			return nil, nil
+		}
+		d.logger.Error("Failed to get SOA record", "error", err)
+		return nil, err
+	}
+	return &record, nil
+}
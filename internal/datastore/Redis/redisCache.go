@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"strings"
 	"time"
 
 	"github.com/Unfield/Odin-DNS/internal/datastore"
@@ -23,19 +24,25 @@ type RedisCacheDriver struct {
 }
 
 func NewRedisCacheDriver(persistentDriver datastore.Driver, addr, username, password string, db int) *RedisCacheDriver {
+	return NewRedisCacheDriverWithClient(persistentDriver, redis.NewClient(&redis.Options{
+		Addr:     addr,
+		Username: username,
+		Password: password,
+		DB:       db,
+		TLSConfig: &tls.Config{
+			MinVersion: tls.VersionTLS12,
+		},
+	}))
+}
+
+// NewRedisCacheDriverWithClient caches persistentDriver in a Redis the caller has
+// connected to, e.g. a local one without TLS in tests.
+func NewRedisCacheDriverWithClient(persistentDriver datastore.Driver, client *redis.Client) *RedisCacheDriver {
 	return &RedisCacheDriver{
-		redisClient: redis.NewClient(&redis.Options{
-			Addr:     addr,
-			Username: username,
-			Password: password,
-			DB:       db,
-			TLSConfig: &tls.Config{
-				MinVersion: tls.VersionTLS12,
-			},
-		}),
-		Driver:  persistentDriver,
-		logger:  slog.Default().WithGroup("Redis-Driver"),
-		context: context.Background(),
+		redisClient: client,
+		Driver:      persistentDriver,
+		logger:      slog.Default().WithGroup("Redis-Driver"),
+		context:     context.Background(),
 	}
 }
 
@@ -43,132 +50,659 @@ func (d *RedisCacheDriver) Close() error {
 	return d.redisClient.Close()
 }
 
-func (d *RedisCacheDriver) LookupRecordForDNSQuery(rname string, rtype uint16, rclass uint16) (*odintypes.DNSRecord, uint8, error) {
+func (d *RedisCacheDriver) LookupRecordForDNSQuery(zoneID string, rname string, rtype uint16, rclass uint16) ([]*odintypes.DNSRecord, uint8, error) {
 	rTypeStr := odintypes.TypeToString(rtype)
 	rClassStr := odintypes.ClassToString(rclass)
-	cacheKey := combineSearchPartsToKey(rname, rtype, rclass)
+	cacheKey := combineSearchPartsToKey(zoneID, rname, rtype, rclass)
 
 	cacheEntry, err := d.redisClient.Get(d.context, cacheKey).Result()
 	if err != nil {
 		if err == redis.Nil {
 			d.logger.Info("Cache miss", "name", rname, "type", rTypeStr, "class", rClassStr)
-			dbRecordFromPersistent, _, err := d.Driver.LookupRecordForDNSQuery(rname, rtype, rclass)
+			rrsetFromPersistent, _, err := d.Driver.LookupRecordForDNSQuery(zoneID, rname, rtype, rclass)
 			if err != nil {
 				return nil, 0, err
 			}
-			if dbRecordFromPersistent == nil {
-				d.logger.Info("Record not found in persistent store", "name", rname)
-				return nil, 0, err
-			}
-
-			rDataStringForCache := util.ConvertRDataBytesToString(dbRecordFromPersistent.Type, dbRecordFromPersistent.RData)
-			if rDataStringForCache == "" && len(dbRecordFromPersistent.RData) > 0 {
-				d.logger.Warn("Failed to convert RData bytes to string for caching; not caching this RData.",
-					"type", dbRecordFromPersistent.Type, "rname", rname)
-			}
-
-			cacheableRecord := types.CacheRecord{
-				Name:  dbRecordFromPersistent.Name,
-				Type:  odintypes.TypeToString(dbRecordFromPersistent.Type),
-				Class: odintypes.ClassToString(dbRecordFromPersistent.Class),
-				TTL:   dbRecordFromPersistent.TTL,
-				RData: rDataStringForCache,
+			if len(rrsetFromPersistent) == 0 {
+				d.logger.Info("RRset not found in persistent store", "name", rname)
+				d.cacheRRset(cacheKey, nil)
+				return nil, 0, nil
 			}
 
-			recordJSONBytes, marshalErr := json.Marshal(cacheableRecord)
-			if marshalErr != nil {
-				d.logger.Error("Failed to marshal DNS record for caching", "error", marshalErr, "record", cacheableRecord)
-			} else {
-				cacheTTL := time.Duration(dbRecordFromPersistent.TTL) * time.Second
-				if cacheTTL <= 0 {
-					cacheTTL = 5 * time.Minute
-				}
-
-				if setErr := d.redisClient.Set(d.context, cacheKey, recordJSONBytes, cacheTTL).Err(); setErr != nil {
-					d.logger.Error("Failed to set DNS record in cache", "error", setErr, "key", cacheKey)
-				} else {
-					d.logger.Info("Record cached successfully", "name", rname, "type", rTypeStr, "class", rClassStr, "ttl", cacheTTL)
-				}
-			}
-			return dbRecordFromPersistent, 0, nil
+			d.cacheRRset(cacheKey, rrsetFromPersistent)
+			return rrsetFromPersistent, 0, nil
 		} else {
 			d.logger.Error("Failed to retrieve data from cache", "error", err, "key", cacheKey)
 			return nil, 0, fmt.Errorf("cache query failed for %s (%s, %s): %w", rname, rTypeStr, rClassStr, err)
 		}
 	}
 
-	var cachedDBRecord types.CacheRecord
-	if err := json.Unmarshal([]byte(cacheEntry), &cachedDBRecord); err != nil {
-		d.logger.Error("Failed to unmarshal DNS record from cache (corrupted?)", "error", err, "cache_entry", cacheEntry)
+	var cachedRecords []types.CacheRecord
+	if err := json.Unmarshal([]byte(cacheEntry), &cachedRecords); err != nil {
+		d.logger.Error("Failed to unmarshal RRset from cache (corrupted?)", "error", err, "cache_entry", cacheEntry)
 		d.redisClient.Del(d.context, cacheKey)
 		d.logger.Info("Attempting to fetch from persistent store after unmarshal error", "name", rname)
-		return d.Driver.LookupRecordForDNSQuery(rname, rtype, rclass)
+		return d.Driver.LookupRecordForDNSQuery(zoneID, rname, rtype, rclass)
+	}
+	if len(cachedRecords) == 0 {
+		d.logger.Info("Negative cache hit", "name", rname, "type", rTypeStr, "class", rClassStr)
+		return nil, 1, nil
 	}
 
-	packedRData, convErr := util.ConvertRDataStringToBytes(rtype, cachedDBRecord.RData)
-	if convErr != nil {
-		d.logger.Error("Failed to convert RData string to bytes from cache entry (corrupted?)",
-			"type", cachedDBRecord.Type, "rdata_string", cachedDBRecord.RData, "error", convErr)
-		d.redisClient.Del(d.context, cacheKey)
-		d.logger.Info("Attempting to fetch from persistent store after RData conversion error", "name", rname)
-		return d.Driver.LookupRecordForDNSQuery(rname, rtype, rclass)
+	rrset := make([]*odintypes.DNSRecord, 0, len(cachedRecords))
+	for _, cachedRecord := range cachedRecords {
+		packedRData, convErr := util.ConvertRDataStringToBytes(rtype, cachedRecord.RData)
+		if convErr != nil {
+			d.logger.Error("Failed to convert RData string to bytes from cache entry (corrupted?)",
+				"type", cachedRecord.Type, "rdata_string", cachedRecord.RData, "error", convErr)
+			d.redisClient.Del(d.context, cacheKey)
+			d.logger.Info("Attempting to fetch from persistent store after RData conversion error", "name", rname)
+			return d.Driver.LookupRecordForDNSQuery(zoneID, rname, rtype, rclass)
+		}
+
+		rrset = append(rrset, &odintypes.DNSRecord{
+			Name:  cachedRecord.Name,
+			Type:  rtype,
+			Class: rclass,
+			TTL:   cachedRecord.TTL,
+			RData: packedRData,
+		})
 	}
 
-	d.logger.Info("Cache hit", "name", rname, "type", rTypeStr, "class", rClassStr)
+	d.logger.Info("Cache hit", "name", rname, "type", rTypeStr, "class", rClassStr, "records", len(rrset))
 
-	return &odintypes.DNSRecord{
-		Name:  cachedDBRecord.Name,
-		Type:  rtype,
-		Class: rclass,
-		TTL:   cachedDBRecord.TTL,
-		RData: packedRData,
-	}, 1, nil
+	return rrset, 1, nil
 }
 
-func combineSearchPartsToKey(rname string, rtype uint16, rclass uint16) string {
-	return fmt.Sprintf("%s|%d|%d", rname, rtype, rclass)
+// negativeCacheTTL bounds how long an RRset that does not exist is cached. Writes
+// through this driver evict it right away, so it only delays changes made elsewhere.
+const negativeCacheTTL = 30 * time.Second
+
+// cacheRRset stores rrset under cacheKey for the TTL of its records. An empty rrset
+// is cached for negativeCacheTTL, so repeated lookups of missing data stay off the
+// persistent store.
+func (d *RedisCacheDriver) cacheRRset(cacheKey string, rrset []*odintypes.DNSRecord) {
+	cacheableRecords := make([]types.CacheRecord, 0, len(rrset))
+	for _, record := range rrset {
+		rDataStringForCache := util.ConvertRDataBytesToString(record.Type, record.RData)
+		if rDataStringForCache == "" && len(record.RData) > 0 {
+			d.logger.Warn("Failed to convert RData bytes to string for caching; not caching this RRset.",
+				"type", record.Type, "rname", record.Name)
+			return
+		}
+
+		cacheableRecords = append(cacheableRecords, types.CacheRecord{
+			Name:  record.Name,
+			Type:  odintypes.TypeToString(record.Type),
+			Class: odintypes.ClassToString(record.Class),
+			TTL:   record.TTL,
+			RData: rDataStringForCache,
+		})
+	}
+
+	recordJSONBytes, marshalErr := json.Marshal(cacheableRecords)
+	if marshalErr != nil {
+		d.logger.Error("Failed to marshal RRset for caching", "error", marshalErr, "key", cacheKey)
+		return
+	}
+
+	cacheTTL := negativeCacheTTL
+	if len(rrset) > 0 {
+		cacheTTL = time.Duration(rrset[0].TTL) * time.Second
+		if cacheTTL <= 0 {
+			cacheTTL = 5 * time.Minute
+		}
+	}
+
+	if setErr := d.redisClient.Set(d.context, cacheKey, recordJSONBytes, cacheTTL).Err(); setErr != nil {
+		d.logger.Error("Failed to set RRset in cache", "error", setErr, "key", cacheKey)
+	} else {
+		d.logger.Info("RRset cached successfully", "key", cacheKey, "records", len(cacheableRecords), "ttl", cacheTTL)
+	}
 }
 
-func (d *RedisCacheDriver) CreateRecord(record *types.DBRecord) error {
-	d.logger.Info("Creating record in persistent store",
-		"name", record.Name, "type", record.Type, "class", record.Class)
-	if err := d.Driver.CreateRecord(record); err != nil {
-		return fmt.Errorf("failed to create record in persistent store: %w", err)
+// LookupAddressRecords serves the A and AAAA RRsets of several names from the cache
+// with one MGET and loads everything that is missing with one persistent lookup.
+func (d *RedisCacheDriver) LookupAddressRecords(zoneID string, rnames []string, rclass uint16) ([]*odintypes.DNSRecord, uint8, error) {
+	if len(rnames) == 0 {
+		return nil, 0, nil
+	}
+
+	addressTypes := []uint16{odintypes.TYPE_A, odintypes.TYPE_AAAA}
+	cacheKeys := make([]string, 0, len(rnames)*len(addressTypes))
+	for _, rname := range rnames {
+		for _, rtype := range addressTypes {
+			cacheKeys = append(cacheKeys, combineSearchPartsToKey(zoneID, rname, rtype, rclass))
+		}
+	}
+
+	cacheEntries, err := d.redisClient.MGet(d.context, cacheKeys...).Result()
+	if err != nil {
+		d.logger.Error("Failed to retrieve address records from cache", "error", err, "names", rnames)
+		return nil, 0, fmt.Errorf("cache query failed for addresses of %v: %w", rnames, err)
+	}
+
+	var records []*odintypes.DNSRecord
+	missingNames := map[string]bool{}
+	for i, cacheEntry := range cacheEntries {
+		rname := strings.ToLower(rnames[i/len(addressTypes)])
+		rtype := addressTypes[i%len(addressTypes)]
+
+		entry, ok := cacheEntry.(string)
+		if !ok {
+			missingNames[rname] = true
+			continue
+		}
+
+		var cachedRecords []types.CacheRecord
+		if err := json.Unmarshal([]byte(entry), &cachedRecords); err != nil {
+			d.redisClient.Del(d.context, cacheKeys[i])
+			missingNames[rname] = true
+			continue
+		}
+		for _, cachedRecord := range cachedRecords {
+			packedRData, convErr := util.ConvertRDataStringToBytes(rtype, cachedRecord.RData)
+			if convErr != nil {
+				d.redisClient.Del(d.context, cacheKeys[i])
+				missingNames[rname] = true
+				break
+			}
+			records = append(records, &odintypes.DNSRecord{
+				Name:  cachedRecord.Name,
+				Type:  rtype,
+				Class: rclass,
+				TTL:   cachedRecord.TTL,
+				RData: packedRData,
+			})
+		}
+	}
+
+	if len(missingNames) == 0 {
+		return records, 1, nil
+	}
+
+	// drop whatever was found for names we have to reload so no RRset ends up twice
+	complete := records[:0]
+	for _, record := range records {
+		if !missingNames[strings.ToLower(record.Name)] {
+			complete = append(complete, record)
+		}
+	}
+	records = complete
+
+	names := make([]string, 0, len(missingNames))
+	for rname := range missingNames {
+		names = append(names, rname)
+	}
+
+	loaded, _, err := d.Driver.LookupAddressRecords(zoneID, names, rclass)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// every missing name gets both address RRsets cached, empty ones included
+	rrsets := map[string][]*odintypes.DNSRecord{}
+	for _, rname := range names {
+		for _, rtype := range addressTypes {
+			rrsets[combineSearchPartsToKey(zoneID, rname, rtype, rclass)] = nil
+		}
+	}
+	for _, record := range loaded {
+		cacheKey := combineSearchPartsToKey(zoneID, record.Name, record.Type, record.Class)
+		rrsets[cacheKey] = append(rrsets[cacheKey], record)
+	}
+	for cacheKey, rrset := range rrsets {
+		d.cacheRRset(cacheKey, rrset)
+	}
+
+	return append(records, loaded...), 0, nil
+}
+
+// namesCacheKey is the key of the set of names existing in a zone: every owner name
+// and all of its ancestors, every owner name once more as ownerMember and
+// namesLoadedMarker, so an empty zone is cached too.
+func namesCacheKey(zoneID string, rclass uint16) string {
+	return fmt.Sprintf("names|%s|%d", zoneID, rclass)
+}
+
+const namesLoadedMarker = "|"
+
+func ownerMember(name string) string {
+	return "owner|" + strings.ToLower(name)
+}
+
+// NameExists answers from the cached name set of the zone, which is loaded from the
+// persistent store as a whole the first time it is needed. Writes through this driver
+// drop the set, zoneCacheTTL bounds how long changes made elsewhere go unnoticed.
+func (d *RedisCacheDriver) NameExists(zoneID string, rname string, rclass uint16) (bool, error) {
+	return d.zoneNamesContain(zoneID, strings.ToLower(rname), rclass)
+}
+
+// OwnsRecords answers from the same cached name set as NameExists.
+func (d *RedisCacheDriver) OwnsRecords(zoneID string, rname string, rclass uint16) (bool, error) {
+	return d.zoneNamesContain(zoneID, ownerMember(rname), rclass)
+}
+
+func (d *RedisCacheDriver) zoneNamesContain(zoneID string, member string, rclass uint16) (bool, error) {
+	cacheKey := namesCacheKey(zoneID, rclass)
+
+	var loaded *redis.IntCmd
+	var exists *redis.BoolCmd
+	_, err := d.redisClient.TxPipelined(d.context, func(pipe redis.Pipeliner) error {
+		loaded = pipe.Exists(d.context, cacheKey)
+		exists = pipe.SIsMember(d.context, cacheKey, member)
+		return nil
+	})
+	if err != nil {
+		d.logger.Error("Failed to retrieve zone names from cache", "error", err, "key", cacheKey)
+		return false, fmt.Errorf("cache query failed for existence of %s: %w", member, err)
+	}
+	if loaded.Val() == 1 {
+		return exists.Val(), nil
+	}
+
+	names, err := d.loadZoneNames(zoneID, rclass)
+	if err != nil {
+		return false, err
+	}
+	return names[member], nil
+}
+
+func (d *RedisCacheDriver) loadZoneNames(zoneID string, rclass uint16) (map[string]bool, error) {
+	owners, err := d.Driver.LookupOwnerNames(zoneID, rclass)
+	if err != nil {
+		return nil, err
+	}
+
+	names := map[string]bool{}
+	members := []any{namesLoadedMarker}
+	for _, owner := range owners {
+		if !names[ownerMember(owner)] {
+			names[ownerMember(owner)] = true
+			members = append(members, ownerMember(owner))
+		}
+		for name := strings.ToLower(owner); name != "" && !names[name]; name = util.ParentDomain(name) {
+			names[name] = true
+			members = append(members, name)
+		}
+	}
+
+	cacheKey := namesCacheKey(zoneID, rclass)
+	_, err = d.redisClient.TxPipelined(d.context, func(pipe redis.Pipeliner) error {
+		pipe.Del(d.context, cacheKey)
+		pipe.SAdd(d.context, cacheKey, members...)
+		pipe.Expire(d.context, cacheKey, zoneCacheTTL)
+		return nil
+	})
+	if err != nil {
+		d.logger.Error("Failed to cache zone names", "error", err, "key", cacheKey)
 	}
 
-	recordJSONBytes, err := json.Marshal(record)
+	return names, nil
+}
+
+// zoneCacheTTL bounds how long the served zones are cached. Writes through this
+// driver invalidate them earlier, the TTL covers changes made elsewhere.
+const zoneCacheTTL = 5 * time.Minute
+
+// zonesCacheKey is the key of a hash holding every served zone under its lowercase
+// name, plus zonesLoadedMarker. Caching the zones as a whole keeps the number of
+// keys independent of the names clients query.
+const (
+	zonesCacheKey     = "zones"
+	zonesLoadedMarker = "|"
+)
+
+// FindZoneForName returns the zone with the longest name that name lies in. All
+// candidate zone names are looked up in the cached zones with one HMGET; the
+// persistent store is only asked when the zones are not cached.
+func (d *RedisCacheDriver) FindZoneForName(name string) (*types.DBZone, error) {
+	fields := []string{zonesLoadedMarker}
+	for candidate := name; candidate != ""; candidate = util.ParentDomain(candidate) {
+		fields = append(fields, strings.ToLower(candidate))
+	}
+
+	cacheEntries, err := d.redisClient.HMGet(d.context, zonesCacheKey, fields...).Result()
 	if err != nil {
-		d.logger.Error("Failed to marshal record for caching during creation", "error", err, "record", record)
+		d.logger.Error("Failed to retrieve zones from cache", "error", err, "name", name)
+		return nil, fmt.Errorf("cache query failed for zone of %s: %w", name, err)
+	}
+	if cacheEntries[0] == nil {
+		return d.loadZoneForName(fields[1:])
+	}
+
+	for _, cacheEntry := range cacheEntries[1:] {
+		entry, ok := cacheEntry.(string)
+		if !ok {
+			continue
+		}
+
+		var zone types.DBZone
+		if err := json.Unmarshal([]byte(entry), &zone); err != nil {
+			d.logger.Error("Failed to unmarshal zone from cache (corrupted?)", "error", err, "cache_entry", entry)
+			return d.loadZoneForName(fields[1:])
+		}
+		return &zone, nil
+	}
+	return nil, nil
+}
+
+// loadZoneForName caches all served zones and returns the first of candidates
+// among them.
+func (d *RedisCacheDriver) loadZoneForName(candidates []string) (*types.DBZone, error) {
+	zones, err := d.Driver.GetAllZones()
+	if err != nil {
+		return nil, err
+	}
+
+	byName := make(map[string]*types.DBZone, len(zones))
+	values := []any{zonesLoadedMarker, ""}
+	for i := range zones {
+		zoneName := strings.ToLower(zones[i].Name)
+		byName[zoneName] = &zones[i]
+
+		zoneJSONBytes, err := json.Marshal(zones[i])
+		if err != nil {
+			d.logger.Error("Failed to marshal zone for caching", "error", err, "zone", zones[i].Name)
+			continue
+		}
+		values = append(values, zoneName, zoneJSONBytes)
+	}
+
+	_, err = d.redisClient.TxPipelined(d.context, func(pipe redis.Pipeliner) error {
+		pipe.Del(d.context, zonesCacheKey)
+		pipe.HSet(d.context, zonesCacheKey, values...)
+		pipe.Expire(d.context, zonesCacheKey, zoneCacheTTL)
 		return nil
+	})
+	if err != nil {
+		d.logger.Error("Failed to cache zones", "error", err)
+	}
+
+	for _, candidate := range candidates {
+		if zone, ok := byName[candidate]; ok {
+			return zone, nil
+		}
+	}
+	return nil, nil
+}
+
+func (d *RedisCacheDriver) invalidateZones() {
+	if err := d.redisClient.Del(d.context, zonesCacheKey).Err(); err != nil {
+		d.logger.Error("Failed to invalidate cached zones", "error", err)
+	}
+}
+
+func (d *RedisCacheDriver) CreateZone(zone *types.DBZone) error {
+	if err := d.Driver.CreateZone(zone); err != nil {
+		return err
+	}
+	d.invalidateZones()
+	return nil
+}
+
+func (d *RedisCacheDriver) UpdateZone(zone *types.DBZone) error {
+	if err := d.Driver.UpdateZone(zone); err != nil {
+		return err
+	}
+	d.invalidateZones()
+	return nil
+}
+
+func (d *RedisCacheDriver) DeleteZone(id string) error {
+	if err := d.Driver.DeleteZone(id); err != nil {
+		return err
+	}
+	d.invalidateZones()
+	return nil
+}
+
+// aliasNegativeTTL bounds how long an ALIAS target without addresses is remembered.
+const aliasNegativeTTL = time.Minute
+
+// GetAliasTarget returns the addresses of an external ALIAS target cached by
+// CacheAliasTarget. found is false on a cache miss, an empty result is a valid hit.
+func (d *RedisCacheDriver) GetAliasTarget(target string, rtype uint16, rclass uint16) ([]*odintypes.DNSRecord, bool, error) {
+	cacheKey := aliasCacheKey(target, rtype, rclass)
+
+	cacheEntry, err := d.redisClient.Get(d.context, cacheKey).Result()
+	if err == redis.Nil {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("cache query failed for ALIAS target %s: %w", target, err)
+	}
+
+	var cachedRecords []types.CacheRecord
+	if err := json.Unmarshal([]byte(cacheEntry), &cachedRecords); err != nil {
+		d.redisClient.Del(d.context, cacheKey)
+		return nil, false, fmt.Errorf("corrupted ALIAS cache entry for %s: %w", target, err)
+	}
+
+	records := make([]*odintypes.DNSRecord, 0, len(cachedRecords))
+	for _, cachedRecord := range cachedRecords {
+		packedRData, err := util.ConvertRDataStringToBytes(rtype, cachedRecord.RData)
+		if err != nil {
+			d.redisClient.Del(d.context, cacheKey)
+			return nil, false, fmt.Errorf("corrupted ALIAS cache entry for %s: %w", target, err)
+		}
+		records = append(records, &odintypes.DNSRecord{
+			Name:  cachedRecord.Name,
+			Type:  rtype,
+			Class: rclass,
+			TTL:   cachedRecord.TTL,
+			RData: packedRData,
+		})
 	}
 
+	d.logger.Info("ALIAS target cache hit", "target", target, "type", odintypes.TypeToString(rtype), "records", len(records))
+	return records, true, nil
+}
+
+// CacheAliasTarget remembers the addresses of an external ALIAS target for the
+// lowest TTL among them. Targets without addresses are cached for aliasNegativeTTL.
+func (d *RedisCacheDriver) CacheAliasTarget(target string, rtype uint16, rclass uint16, records []*odintypes.DNSRecord) {
+	cacheKey := aliasCacheKey(target, rtype, rclass)
+
+	cacheTTL := aliasNegativeTTL
+	cacheableRecords := make([]types.CacheRecord, 0, len(records))
+	for i, record := range records {
+		if i == 0 || time.Duration(record.TTL)*time.Second < cacheTTL {
+			cacheTTL = time.Duration(record.TTL) * time.Second
+		}
+		cacheableRecords = append(cacheableRecords, types.CacheRecord{
+			Name:  record.Name,
+			Type:  odintypes.TypeToString(record.Type),
+			Class: odintypes.ClassToString(record.Class),
+			TTL:   record.TTL,
+			RData: util.ConvertRDataBytesToString(record.Type, record.RData),
+		})
+	}
+	if cacheTTL <= 0 {
+		return
+	}
+
+	recordJSONBytes, err := json.Marshal(cacheableRecords)
+	if err != nil {
+		d.logger.Error("Failed to marshal ALIAS target for caching", "error", err, "key", cacheKey)
+		return
+	}
+	if err := d.redisClient.Set(d.context, cacheKey, recordJSONBytes, cacheTTL).Err(); err != nil {
+		d.logger.Error("Failed to set ALIAS target in cache", "error", err, "key", cacheKey)
+	}
+}
+
+func aliasCacheKey(target string, rtype uint16, rclass uint16) string {
+	return fmt.Sprintf("alias|%s|%d|%d", strings.ToLower(target), rtype, rclass)
+}
+
+// combineSearchPartsToKey builds the cache key of an RRset. Parent and child zones
+// can both hold records for the same name, so the zone is part of the key.
+func combineSearchPartsToKey(zoneID string, rname string, rtype uint16, rclass uint16) string {
+	return fmt.Sprintf("%s|%s|%d|%d", zoneID, strings.ToLower(rname), rtype, rclass)
+}
+
+// invalidateRRset drops the cached RRset a stored record belongs to, together with
+// the name set of its zone. RRsets are cached as a whole, so any write to one of its
+// records has to evict the entire set.
+func (d *RedisCacheDriver) invalidateRRset(record *types.DBRecord) {
 	recordTypeUint, parseTypeErr := odintypes.StringToType(record.Type)
 	recordClassUint, parseClassErr := odintypes.StringToClass(record.Class)
 
 	if parseTypeErr != nil || parseClassErr != nil {
-		d.logger.Error("Failed to parse record type/class for cache key during creation",
+		d.logger.Error("Failed to parse record type/class for cache key",
 			"type_str", record.Type, "class_str", record.Class,
 			"type_err", parseTypeErr, "class_err", parseClassErr)
-		return nil
+		return
 	}
 
-	cacheKey := combineSearchPartsToKey(record.Name, recordTypeUint, recordClassUint)
-	cacheTTL := time.Duration(record.TTL) * time.Second
-	if cacheTTL <= 0 {
-		cacheTTL = 5 * time.Minute
+	cacheKey := combineSearchPartsToKey(record.ZoneID, record.Name, recordTypeUint, recordClassUint)
+	if err := d.redisClient.Del(d.context, cacheKey, namesCacheKey(record.ZoneID, recordClassUint)).Err(); err != nil {
+		d.logger.Error("Failed to invalidate cached RRset", "error", err, "key", cacheKey)
+		return
 	}
 
-	if setErr := d.redisClient.Set(d.context, cacheKey, recordJSONBytes, cacheTTL).Err(); setErr != nil {
-		d.logger.Error("Failed to cache record during creation", "error", setErr, "key", cacheKey)
-		return nil
+	d.logger.Info("Cached RRset invalidated", "name", record.Name, "type", record.Type, "class", record.Class)
+}
+
+func (d *RedisCacheDriver) CreateRecord(record *types.DBRecord) error {
+	d.logger.Info("Creating record in persistent store",
+		"name", record.Name, "type", record.Type, "class", record.Class)
+	if err := d.Driver.CreateRecord(record); err != nil {
+		return fmt.Errorf("failed to create record in persistent store: %w", err)
+	}
+
+	d.invalidateRRset(record)
+
+	return nil
+}
+
+func (d *RedisCacheDriver) UpdateRecord(record *types.DBRecord) error {
+	previous, err := d.Driver.GetRecord(record.ID)
+	if err != nil {
+		return fmt.Errorf("failed to load record before update: %w", err)
+	}
+
+	if err := d.Driver.UpdateRecord(record); err != nil {
+		return err
+	}
+
+	if previous != nil {
+		d.invalidateRRset(previous)
+	}
+	d.invalidateRRset(record)
+
+	return nil
+}
+
+func (d *RedisCacheDriver) DeleteRecord(id string) error {
+	previous, err := d.Driver.GetRecord(id)
+	if err != nil {
+		return fmt.Errorf("failed to load record before delete: %w", err)
+	}
+
+	if err := d.Driver.DeleteRecord(id); err != nil {
+		return err
+	}
+
+	if previous != nil {
+		d.invalidateRRset(previous)
+	}
+
+	return nil
+}
+
+// ApplyZoneChange evicts every RRset the change touched, including the zone's SOA.
+func (d *RedisCacheDriver) ApplyZoneChange(zoneId string, deleted []types.DBRecord, added []types.DBRecord) (*types.DBRecord, error) {
+	soa, err := d.Driver.ApplyZoneChange(zoneId, deleted, added)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, record := range deleted {
+		d.invalidateRRset(&record)
+	}
+	for _, record := range added {
+		d.invalidateRRset(&record)
 	}
+	d.invalidateRRset(soa)
+	d.publishZoneChange(zoneId)
 
-	d.logger.Info("Record successfully created and cached",
-		"name", record.Name, "type", record.Type, "class", record.Class, "ttl", cacheTTL)
+	return soa, nil
+}
+
+// ReplaceZoneRecords evicts the RRsets of the zone before and after the transfer.
+func (d *RedisCacheDriver) ReplaceZoneRecords(zoneId string, records []types.DBRecord) error {
+	previous, err := d.Driver.GetZoneEntries(zoneId)
+	if err != nil {
+		return fmt.Errorf("failed to load records before replace: %w", err)
+	}
+
+	if err := d.Driver.ReplaceZoneRecords(zoneId, records); err != nil {
+		return err
+	}
+
+	for _, record := range previous {
+		d.invalidateRRset(&record)
+	}
+	for _, record := range records {
+		d.invalidateRRset(&record)
+	}
+	d.publishZoneChange(zoneId)
 
 	return nil
 }
 
+func (d *RedisCacheDriver) ApplyZoneDelta(zoneId string, deleted []types.DBRecord, added []types.DBRecord) error {
+	if err := d.Driver.ApplyZoneDelta(zoneId, deleted, added); err != nil {
+		return err
+	}
+
+	for _, record := range deleted {
+		d.invalidateRRset(&record)
+	}
+	for _, record := range added {
+		d.invalidateRRset(&record)
+	}
+	d.publishZoneChange(zoneId)
+
+	return nil
+}
+
+// SetZoneExpiry drops the cached zones, which carry the expiry the resolver checks.
+func (d *RedisCacheDriver) SetZoneExpiry(zoneId string, expiresAt time.Time) error {
+	if err := d.Driver.SetZoneExpiry(zoneId, expiresAt); err != nil {
+		return err
+	}
+	d.invalidateZones()
+	return nil
+}
+
+// zoneChangesChannel carries the IDs of zones changed through this driver, so every
+// DNS server can notify the zone's secondaries.
+const zoneChangesChannel = "zone-changes"
+
+func (d *RedisCacheDriver) publishZoneChange(zoneId string) {
+	if err := d.redisClient.Publish(d.context, zoneChangesChannel, zoneId).Err(); err != nil {
+		d.logger.Error("Failed to publish zone change", "error", err, "zone_id", zoneId)
+	}
+}
+
+// SubscribeZoneChanges returns the IDs of zones changed from now on, from any
+// process sharing this cache.
+func (d *RedisCacheDriver) SubscribeZoneChanges() <-chan string {
+	messages := d.redisClient.Subscribe(d.context, zoneChangesChannel).Channel()
+
+	zoneIDs := make(chan string)
+	go func() {
+		defer close(zoneIDs)
+		for message := range messages {
+			zoneIDs <- message.Payload
+		}
+	}()
+	return zoneIDs
+}
+
 func (d *RedisCacheDriver) CreateSession(session *types.Session) error {
 	d.logger.Info("Creating session in persistent store", "session_id", session.ID, "user_id", session.UserID)
 	if err := d.Driver.CreateSession(session); err != nil {
@@ -1,6 +1,8 @@
 package datastore
 
 import (
+	"time"
+
 	"github.com/Unfield/Odin-DNS/internal/types"
 	"github.com/Unfield/Odin-DNS/pkg/odintypes"
 )
@@ -27,9 +29,33 @@ type Driver interface {
 	DeleteRecord(id string) error
 	GetFullZone(name string) (*types.DBZone, []types.DBRecord, error)
 	GetFullZoneById(id string) (*types.DBZone, []types.DBRecord, error)
+	GetZoneSOA(zoneId string) (*types.DBRecord, error)
+	ApplyZoneChange(zoneId string, deleted []types.DBRecord, added []types.DBRecord) (*types.DBRecord, error)
+	GetZoneJournal(zoneId string, fromSerial uint32) ([]types.DBJournalEntry, error)
+	PruneZoneJournal(before time.Time) (int64, error)
 
 	GetZones(owner string) ([]types.DBZone, error)
+	GetAllZones() ([]types.DBZone, error)
 	GetZoneEntries(zoneId string) ([]types.DBRecord, error)
 
-	LookupRecordForDNSQuery(rname string, rtype uint16, rclass uint16) (*odintypes.DNSRecord, uint8, error)
+	GetZoneTransferACL(zoneId string) ([]types.DBZoneTransferACL, error)
+	CreateZoneTransferACL(entry *types.DBZoneTransferACL) error
+	DeleteZoneTransferACL(zoneId string, id string) error
+
+	GetZoneSecondaries(zoneId string) ([]types.DBZoneSecondary, error)
+	CreateZoneSecondary(secondary *types.DBZoneSecondary) error
+	DeleteZoneSecondary(zoneId string, id string) error
+
+	GetSecondaryZones() ([]types.DBZone, error)
+	ReplaceZoneRecords(zoneId string, records []types.DBRecord) error
+	ApplyZoneDelta(zoneId string, deleted []types.DBRecord, added []types.DBRecord) error
+	SetZoneExpiry(zoneId string, expiresAt time.Time) error
+
+	FindZoneForName(name string) (*types.DBZone, error)
+	LookupRecordForDNSQuery(zoneID string, rname string, rtype uint16, rclass uint16) ([]*odintypes.DNSRecord, uint8, error)
+	NameExists(zoneID string, rname string, rclass uint16) (bool, error)
+	OwnsRecords(zoneID string, rname string, rclass uint16) (bool, error)
+	LookupOwnerNames(zoneID string, rclass uint16) ([]string, error)
+	LookupAllRecords(zoneID string, rname string, rclass uint16) ([]*odintypes.DNSRecord, error)
+	LookupAddressRecords(zoneID string, rnames []string, rclass uint16) ([]*odintypes.DNSRecord, uint8, error)
 }
@@ -72,6 +72,8 @@ type RcodeData struct {
 type ZoneResponse struct {
 	ID        string     `json:"id"`
 	Name      string     `json:"name"`
+	Kind      string     `json:"kind" example:"primary"`
+	Primaries []string   `json:"primaries,omitempty"`
 	CreatedAt time.Time  `json:"created_at"`
 	DeletedAt *time.Time `json:"deleted_at,omitempty"`
 }
@@ -82,7 +84,31 @@ type GetZonesResponse struct {
 }
 
 type CreateZoneRequest struct {
-	Name string `json:"name" binding:"required" example:"example.com" description:"Domain name for the zone"`
+	Name      string          `json:"name" binding:"required" example:"example.com" description:"Domain name for the zone"`
+	Kind      string          `json:"kind,omitempty" example:"primary" enums:"primary,secondary" description:"'primary' (default) for zones managed here, 'secondary' for zones transferred from their primaries"`
+	Primaries []string        `json:"primaries,omitempty" example:"192.0.2.1" description:"IP addresses, optionally with port, of the primaries a secondary zone is transferred from"`
+	SOA       *ZoneSOARequest `json:"soa,omitempty" description:"Optional SOA settings, server defaults are used for omitted fields. Not allowed for secondary zones"`
+}
+
+type ZoneSOARequest struct {
+	MName   string  `json:"mname,omitempty" example:"ns1.example.com" description:"Primary name server of the zone"`
+	RName   string  `json:"rname,omitempty" example:"hostmaster.example.com" description:"Mailbox of the zone administrator, '@' is accepted"`
+	TTL     *uint32 `json:"ttl,omitempty" example:"3600" description:"TTL of the SOA record in seconds"`
+	Refresh *uint32 `json:"refresh,omitempty" example:"3600" description:"Seconds between secondary refresh checks"`
+	Retry   *uint32 `json:"retry,omitempty" example:"900" description:"Seconds before a failed refresh is retried"`
+	Expire  *uint32 `json:"expire,omitempty" example:"1209600" description:"Seconds after which secondaries stop answering without refresh"`
+	Minimum *uint32 `json:"minimum,omitempty" example:"300" description:"Negative caching TTL in seconds"`
+}
+
+type ZoneSOAResponse struct {
+	MName   string `json:"mname" example:"ns1.example.com"`
+	RName   string `json:"rname" example:"hostmaster.example.com"`
+	Serial  uint32 `json:"serial" example:"2025070101"`
+	TTL     uint32 `json:"ttl" example:"3600"`
+	Refresh uint32 `json:"refresh" example:"3600"`
+	Retry   uint32 `json:"retry" example:"900"`
+	Expire  uint32 `json:"expire" example:"1209600"`
+	Minimum uint32 `json:"minimum" example:"300"`
 }
 
 type CreateZoneResponse struct {
@@ -90,13 +116,16 @@ type CreateZoneResponse struct {
 }
 
 type ZoneRecordResponse struct {
-	ID       string  `json:"id"`
-	Name     string  `json:"name"`
-	Type     string  `json:"type"`
-	Class    string  `json:"class"`
-	TTl      uint32  `json:"ttl"`
-	Priority *uint16 `json:"priority,omitempty"`
-	Value    string  `json:"value"`
+	ID       string   `json:"id"`
+	Name     string   `json:"name"`
+	Type     string   `json:"type"`
+	Class    string   `json:"class"`
+	TTl      uint32   `json:"ttl"`
+	Priority *uint16  `json:"priority,omitempty"`
+	Weight   *uint16  `json:"weight,omitempty"`
+	Port     *uint16  `json:"port,omitempty"`
+	Value    string   `json:"value"`
+	Values   []string `json:"values,omitempty"`
 }
 
 type GetZoneRecordsResponse struct {
@@ -105,12 +134,15 @@ type GetZoneRecordsResponse struct {
 }
 
 type CreateZoneEntryRequest struct {
-	Name     string  `json:"name" example:"www" description:"Record name (subdomain)"`
-	Type     string  `json:"type" example:"A" description:"DNS record type (A, AAAA, CNAME, MX, TXT, etc.)"`
-	Class    string  `json:"class" example:"IN" description:"DNS record class (typically 'IN')"`
-	TTl      uint32  `json:"ttl" example:"300" description:"Time to live in seconds"`
-	Priority *uint16 `json:"priority,omitempty" example:"10" description:"Priority for MX records (required for MX type)"`
-	Value    string  `json:"value" example:"192.168.1.1" description:"Record value (IP address, hostname, etc.)"`
+	Name     string   `json:"name" example:"www" description:"Record name (subdomain)"`
+	Type     string   `json:"type" example:"A" description:"DNS record type (A, AAAA, CNAME, ALIAS, MX, TXT, etc., or TYPE1234 with a value in RFC 3597 generic syntax)"`
+	Class    string   `json:"class" example:"IN" description:"DNS record class (typically 'IN')"`
+	TTl      uint32   `json:"ttl" example:"300" description:"Time to live in seconds"`
+	Priority *uint16  `json:"priority,omitempty" example:"10" description:"Priority for MX and SRV records (required for these types)"`
+	Weight   *uint16  `json:"weight,omitempty" example:"5" description:"Weight for SRV records (required for SRV type)"`
+	Port     *uint16  `json:"port,omitempty" example:"5060" description:"Port for SRV records (required for SRV type)"`
+	Value    string   `json:"value" example:"192.168.1.1" description:"Record value (IP address, hostname, SRV target, etc.)"`
+	Values   []string `json:"values,omitempty" example:"v=DKIM1; k=rsa; p=MIIBIjANBgkqh" description:"Character-strings of a TXT record, takes precedence over value. Strings longer than 255 bytes are split automatically"`
 }
 
 type CreateZoneEntryResponse struct {
@@ -118,18 +150,23 @@ type CreateZoneEntryResponse struct {
 }
 
 type UpdateZoneEntryRequest struct {
-	Name     string  `json:"name" example:"www" description:"Record name (subdomain)"`
-	Type     string  `json:"type" example:"A" description:"DNS record type (A, AAAA, CNAME, MX, TXT, etc.)"`
-	Class    string  `json:"class" example:"IN" description:"DNS record class (typically 'IN')"`
-	TTl      uint32  `json:"ttl" example:"300" description:"Time to live in seconds"`
-	Priority *uint16 `json:"priority,omitempty" example:"10" description:"Priority for MX records (required for MX type)"`
-	Value    string  `json:"value" example:"192.168.1.1" description:"Record value (IP address, hostname, etc.)"`
+	Name     string   `json:"name" example:"www" description:"Record name (subdomain)"`
+	Type     string   `json:"type" example:"A" description:"DNS record type (A, AAAA, CNAME, ALIAS, MX, TXT, etc., or TYPE1234 with a value in RFC 3597 generic syntax)"`
+	Class    string   `json:"class" example:"IN" description:"DNS record class (typically 'IN')"`
+	TTl      uint32   `json:"ttl" example:"300" description:"Time to live in seconds"`
+	Priority *uint16  `json:"priority,omitempty" example:"10" description:"Priority for MX and SRV records (required for these types)"`
+	Weight   *uint16  `json:"weight,omitempty" example:"5" description:"Weight for SRV records (required for SRV type)"`
+	Port     *uint16  `json:"port,omitempty" example:"5060" description:"Port for SRV records (required for SRV type)"`
+	Value    string   `json:"value" example:"192.168.1.1" description:"Record value (IP address, hostname, SRV target, etc.)"`
+	Values   []string `json:"values,omitempty" example:"v=DKIM1; k=rsa; p=MIIBIjANBgkqh" description:"Character-strings of a TXT record, takes precedence over value. Strings longer than 255 bytes are split automatically"`
 }
 
 type GetZoneResponse struct {
-	Id    string `json:"id"`
-	Name  string `json:"name"`
-	Owner string `json:"owner"`
+	Id        string   `json:"id"`
+	Name      string   `json:"name"`
+	Owner     string   `json:"owner"`
+	Kind      string   `json:"kind" example:"primary"`
+	Primaries []string `json:"primaries,omitempty"`
 }
 
 type UpdateZoneEntryResponse struct {
@@ -143,3 +180,39 @@ type DeleteZoneEntryResponse struct {
 type DeleteZoneResponse struct {
 	Id string `json:"id"`
 }
+
+type CreateZoneTransferACLRequest struct {
+	Network string `json:"network" example:"192.0.2.53" description:"IP address or CIDR range of a secondary that may transfer the zone"`
+}
+
+type ZoneTransferACLResponse struct {
+	Id      string `json:"id"`
+	Network string `json:"network" example:"192.0.2.0/24"`
+}
+
+type GetZoneTransferACLResponse struct {
+	Count   int                       `json:"count"`
+	Entries []ZoneTransferACLResponse `json:"entries"`
+}
+
+type DeleteZoneTransferACLResponse struct {
+	Id string `json:"id"`
+}
+
+type CreateZoneSecondaryRequest struct {
+	Address string `json:"address" example:"192.0.2.53" description:"IP address of a secondary, optionally with port, that is sent NOTIFY messages when the zone changes"`
+}
+
+type ZoneSecondaryResponse struct {
+	Id      string `json:"id"`
+	Address string `json:"address" example:"192.0.2.53:53"`
+}
+
+type GetZoneSecondariesResponse struct {
+	Count       int                     `json:"count"`
+	Secondaries []ZoneSecondaryResponse `json:"secondaries"`
+}
+
+type DeleteZoneSecondaryResponse struct {
+	Id string `json:"id"`
+}
@@ -9,6 +9,15 @@ import (
 	"github.com/Unfield/Odin-DNS/pkg/odintypes"
 )
 
+const (
+	// MaxUDPMessageSize is the RFC 1035 limit for UDP messages without EDNS.
+	MaxUDPMessageSize = 512
+	// MaxTCPMessageSize is the largest message that fits the two byte length prefix used over TCP.
+	MaxTCPMessageSize = 65535
+
+	maxCompressionOffset = 0x3FFF
+)
+
 func PackResponse(response *odintypes.DNSRequest) ([]byte, error) {
 	buf := new(bytes.Buffer)
 
@@ -21,6 +30,15 @@ func PackResponse(response *odintypes.DNSRequest) ([]byte, error) {
 		return nil, fmt.Errorf("failed to pack header flags: %w", err)
 	}
 
+	response.Header.QDCount = uint16(len(response.Questions))
+	response.Header.ANCount = uint16(len(response.Answers))
+	response.Header.NSCount = uint16(len(response.Authority))
+	response.Header.ARCount = uint16(len(response.Additional))
+	if response.EDNS != nil {
+		response.Header.ARCount++
+		response.EDNS.ExtendedRCode = response.Header.Flags.RCode >> 4
+	}
+
 	if err := binary.Write(buf, binary.BigEndian, response.Header.QDCount); err != nil {
 		return nil, fmt.Errorf("failed to pack header QDCount: %w", err)
 	}
@@ -35,7 +53,6 @@ func PackResponse(response *odintypes.DNSRequest) ([]byte, error) {
 	}
 
 	nameOffsets := make(map[string]uint16)
-	currentOffset := uint16(12)
 
 	for _, q := range response.Questions {
 		packedName, err := packDomainName(q.Name, nameOffsets, buf.Len())
@@ -52,44 +69,116 @@ func PackResponse(response *odintypes.DNSRequest) ([]byte, error) {
 		if err := binary.Write(buf, binary.BigEndian, q.Class); err != nil {
 			return nil, fmt.Errorf("failed to pack question class: %w", err)
 		}
-		currentOffset += uint16(len(packedName) + 4)
 	}
 
 	for _, a := range response.Answers {
-		packedName, err := packDomainName(a.Name, nameOffsets, buf.Len())
-		if err != nil {
-			return nil, fmt.Errorf("failed to pack answer name '%s': %w", a.Name, err)
-		}
-		if _, err := buf.Write(packedName); err != nil {
-			return nil, fmt.Errorf("failed to write packed answer name: %w", err)
+		if err := packResourceRecord(a, buf, nameOffsets); err != nil {
+			return nil, fmt.Errorf("failed to pack answer record: %w", err)
 		}
+	}
 
-		if err := binary.Write(buf, binary.BigEndian, a.Type); err != nil {
-			return nil, fmt.Errorf("failed to pack answer type: %w", err)
+	for _, ns := range response.Authority {
+		if err := packResourceRecord(ns, buf, nameOffsets); err != nil {
+			return nil, fmt.Errorf("failed to pack authority record: %w", err)
 		}
-		if err := binary.Write(buf, binary.BigEndian, a.Class); err != nil {
-			return nil, fmt.Errorf("failed to pack answer class: %w", err)
-		}
-		if err := binary.Write(buf, binary.BigEndian, a.TTL); err != nil {
-			return nil, fmt.Errorf("failed to pack answer TTL: %w", err)
+	}
+
+	for _, ar := range response.Additional {
+		if err := packResourceRecord(ar, buf, nameOffsets); err != nil {
+			return nil, fmt.Errorf("failed to pack additional record: %w", err)
 		}
+	}
 
-		rdLengthPos := buf.Len()
-		if err := binary.Write(buf, binary.BigEndian, uint16(0)); err != nil {
-			return nil, fmt.Errorf("failed to write RDLENGTH placeholder: %w", err)
+	if response.EDNS != nil {
+		if err := packResourceRecord(response.EDNS.ToRecord(), buf, nameOffsets); err != nil {
+			return nil, fmt.Errorf("failed to pack OPT record: %w", err)
 		}
+	}
 
-		rdataStartPos := buf.Len()
+	return buf.Bytes(), nil
+}
 
-		if err := packRData(a.Type, a.RData, buf, nameOffsets); err != nil {
-			return nil, fmt.Errorf("failed to pack RData for type %d: %w", a.Type, err)
-		}
+// PackResponseWithLimit packs the response so that it fits into maxSize bytes.
+// Additional records are dropped first (the OPT record is kept); if the message still does not fit, the
+// answer and authority sections are emptied and the TC bit is set so the client
+// retries over TCP.
+func PackResponseWithLimit(response *odintypes.DNSRequest, maxSize int) ([]byte, error) {
+	packed, err := PackResponse(response)
+	if err != nil {
+		return nil, err
+	}
+	if len(packed) <= maxSize {
+		return packed, nil
+	}
 
-		rdataLen := uint16(buf.Len() - rdataStartPos)
-		binary.BigEndian.PutUint16(buf.Bytes()[rdLengthPos:], rdataLen)
+	// glue in a referral is required to reach the delegated servers (RFC 9471),
+	// dropping it means the client has to retry over TCP
+	if isReferral(response) && len(response.Additional) > 0 {
+		response.Header.Flags.TC = true
+	}
+	response.Additional = []*odintypes.DNSRecord{}
+	packed, err = PackResponse(response)
+	if err != nil {
+		return nil, err
+	}
+	if len(packed) <= maxSize {
+		return packed, nil
 	}
 
-	return buf.Bytes(), nil
+	response.Header.Flags.TC = true
+	response.Answers = []*odintypes.DNSRecord{}
+	response.Authority = []*odintypes.DNSRecord{}
+	packed, err = PackResponse(response)
+	if err != nil {
+		return nil, err
+	}
+	if len(packed) > maxSize {
+		return nil, fmt.Errorf("truncated response still exceeds %d bytes: %d", maxSize, len(packed))
+	}
+	return packed, nil
+}
+
+func isReferral(response *odintypes.DNSRequest) bool {
+	return !response.Header.Flags.AA && len(response.Answers) == 0 &&
+		len(response.Authority) > 0 && response.Authority[0].Type == odintypes.TYPE_NS
+}
+
+func packResourceRecord(record *odintypes.DNSRecord, buf *bytes.Buffer, nameOffsets map[string]uint16) error {
+	packedName, err := packDomainName(record.Name, nameOffsets, buf.Len())
+	if err != nil {
+		return fmt.Errorf("failed to pack record name '%s': %w", record.Name, err)
+	}
+	if _, err := buf.Write(packedName); err != nil {
+		return fmt.Errorf("failed to write packed record name: %w", err)
+	}
+
+	if err := binary.Write(buf, binary.BigEndian, record.Type); err != nil {
+		return fmt.Errorf("failed to pack record type: %w", err)
+	}
+	if err := binary.Write(buf, binary.BigEndian, record.Class); err != nil {
+		return fmt.Errorf("failed to pack record class: %w", err)
+	}
+	if err := binary.Write(buf, binary.BigEndian, record.TTL); err != nil {
+		return fmt.Errorf("failed to pack record TTL: %w", err)
+	}
+
+	rdLengthPos := buf.Len()
+	if err := binary.Write(buf, binary.BigEndian, uint16(0)); err != nil {
+		return fmt.Errorf("failed to write RDLENGTH placeholder: %w", err)
+	}
+
+	rdataStartPos := buf.Len()
+
+	if err := packRData(record.Type, record.RData, buf, nameOffsets); err != nil {
+		return fmt.Errorf("failed to pack RData for type %d: %w", record.Type, err)
+	}
+
+	rdataLen := buf.Len() - rdataStartPos
+	if rdataLen > 0xFFFF {
+		return fmt.Errorf("RData for type %d too long: %d bytes", record.Type, rdataLen)
+	}
+	binary.BigEndian.PutUint16(buf.Bytes()[rdLengthPos:], uint16(rdataLen))
+	return nil
 }
 
 func packDomainName(domain string, nameOffsets map[string]uint16, currentBufferLen int) ([]byte, error) {
@@ -105,7 +194,9 @@ func packDomainName(domain string, nameOffsets map[string]uint16, currentBufferL
 	var packedName []byte
 	parts := strings.Split(domain, ".")
 
-	if domain != "" && domain != "." {
+	// compression pointers only have 14 bits, names further into a large TCP
+	// message can not be referenced
+	if domain != "" && domain != "." && currentBufferLen <= maxCompressionOffset {
 		nameOffsets[domain] = uint16(currentBufferLen)
 	}
 
@@ -158,6 +249,16 @@ func packRData(recordType uint16, rData []byte, buf *bytes.Buffer, nameOffsets m
 			return fmt.Errorf("failed to write packed RData domain name: %w", err)
 		}
 
+	case odintypes.TYPE_DNAME:
+		// RFC 6672 2.5 forbids compressing the DNAME target
+		target, err := odintypes.PackUncompressedName(string(rData))
+		if err != nil {
+			return fmt.Errorf("failed to pack DNAME RData target '%s': %w", string(rData), err)
+		}
+		if _, err := buf.Write(target); err != nil {
+			return fmt.Errorf("failed to write DNAME RData target: %w", err)
+		}
+
 	case odintypes.TYPE_MX:
 		if len(rData) < 2 {
 			return fmt.Errorf("MX record RData too short, must contain preference: got %d bytes", len(rData))
@@ -180,15 +281,99 @@ func packRData(recordType uint16, rData []byte, buf *bytes.Buffer, nameOffsets m
 			return fmt.Errorf("failed to write packed MX RData domain name: %w", err)
 		}
 
-	case odintypes.TYPE_TXT:
-		textBytes := rData
-		if len(textBytes) > 255 {
-			return fmt.Errorf("TXT record string too long (max 255 bytes per string segment): %d", len(textBytes))
+	case odintypes.TYPE_SRV:
+		if len(rData) < 7 {
+			return fmt.Errorf("SRV record RData too short, must contain priority, weight, port and target: got %d bytes", len(rData))
+		}
+		if _, err := buf.Write(rData[0:6]); err != nil {
+			return fmt.Errorf("failed to write SRV priority, weight and port: %w", err)
+		}
+
+		// RFC 2782 forbids name compression for the SRV target
+		target, err := odintypes.PackUncompressedName(string(rData[6:]))
+		if err != nil {
+			return fmt.Errorf("failed to pack SRV RData target '%s': %w", string(rData[6:]), err)
+		}
+		if _, err := buf.Write(target); err != nil {
+			return fmt.Errorf("failed to write SRV RData target: %w", err)
+		}
+
+	case odintypes.TYPE_SOA:
+		soa, err := odintypes.UnpackSOAData(rData)
+		if err != nil {
+			return fmt.Errorf("failed to decode SOA RData: %w", err)
 		}
-		if err := binary.Write(buf, binary.BigEndian, byte(len(textBytes))); err != nil {
-			return fmt.Errorf("failed to write TXT RData length: %w", err)
+		for _, name := range []string{soa.MName, soa.RName} {
+			packedDomain, err := packDomainName(name, nameOffsets, buf.Len())
+			if err != nil {
+				return fmt.Errorf("failed to pack SOA RData domain name '%s': %w", name, err)
+			}
+			if _, err := buf.Write(packedDomain); err != nil {
+				return fmt.Errorf("failed to write packed SOA RData domain name: %w", err)
+			}
+		}
+		for _, value := range []uint32{soa.Serial, soa.Refresh, soa.Retry, soa.Expire, soa.Minimum} {
+			if err := binary.Write(buf, binary.BigEndian, value); err != nil {
+				return fmt.Errorf("failed to write SOA RData timers: %w", err)
+			}
+		}
+
+	case odintypes.TYPE_SVCB, odintypes.TYPE_HTTPS:
+		// RFC 9460 2.2 forbids compressing the target name, it is already stored uncompressed
+		if _, err := odintypes.UnpackSVCBData(rData); err != nil {
+			return fmt.Errorf("invalid SVCB RData: %w", err)
 		}
-		if _, err := buf.Write(textBytes); err != nil {
+		if _, err := buf.Write(rData); err != nil {
+			return fmt.Errorf("failed to write SVCB RData: %w", err)
+		}
+
+	case odintypes.TYPE_TLSA, odintypes.TYPE_SMIMEA:
+		if _, err := odintypes.UnpackTLSAData(rData); err != nil {
+			return fmt.Errorf("invalid TLSA RData: %w", err)
+		}
+		if _, err := buf.Write(rData); err != nil {
+			return fmt.Errorf("failed to write TLSA RData: %w", err)
+		}
+
+	case odintypes.TYPE_SSHFP:
+		if _, err := odintypes.UnpackSSHFPData(rData); err != nil {
+			return fmt.Errorf("invalid SSHFP RData: %w", err)
+		}
+		if _, err := buf.Write(rData); err != nil {
+			return fmt.Errorf("failed to write SSHFP RData: %w", err)
+		}
+
+	case odintypes.TYPE_NAPTR:
+		// RFC 3403 4.1 forbids compressing the replacement, it is already stored uncompressed
+		if _, err := odintypes.UnpackNAPTRData(rData); err != nil {
+			return fmt.Errorf("invalid NAPTR RData: %w", err)
+		}
+		if _, err := buf.Write(rData); err != nil {
+			return fmt.Errorf("failed to write NAPTR RData: %w", err)
+		}
+
+	case odintypes.TYPE_LOC:
+		if _, err := odintypes.UnpackLOCData(rData); err != nil {
+			return fmt.Errorf("invalid LOC RData: %w", err)
+		}
+		if _, err := buf.Write(rData); err != nil {
+			return fmt.Errorf("failed to write LOC RData: %w", err)
+		}
+
+	case odintypes.TYPE_CAA:
+		if _, err := odintypes.UnpackCAAData(rData); err != nil {
+			return fmt.Errorf("invalid CAA RData: %w", err)
+		}
+		if _, err := buf.Write(rData); err != nil {
+			return fmt.Errorf("failed to write CAA RData: %w", err)
+		}
+
+	case odintypes.TYPE_TXT:
+		// the RData already holds the length prefixed character-strings
+		if _, err := odintypes.UnpackTXTStrings(rData); err != nil {
+			return fmt.Errorf("invalid TXT RData: %w", err)
+		}
+		if _, err := buf.Write(rData); err != nil {
 			return fmt.Errorf("failed to write TXT RData: %w", err)
 		}
 
@@ -1,6 +1,7 @@
 package parser
 
 import (
+	"encoding/binary"
 	"fmt"
 
 	"github.com/Unfield/Odin-DNS/internal/util"
@@ -38,9 +39,151 @@ func ParseRequest(buffer []byte) (odintypes.DNSRequest, error) {
 
 	request.Questions = qsection
 
+	request.Answers, offset, err = parseRecordSection(buffer, offset, int(header.ANCount), "answer")
+	if err != nil {
+		return odintypes.DNSRequest{}, err
+	}
+	request.Authority, offset, err = parseRecordSection(buffer, offset, int(header.NSCount), "authority")
+	if err != nil {
+		return odintypes.DNSRequest{}, err
+	}
+	additional, _, err := parseRecordSection(buffer, offset, int(header.ARCount), "additional")
+	if err != nil {
+		return odintypes.DNSRequest{}, err
+	}
+
+	for _, record := range additional {
+		if record.Type != odintypes.TYPE_OPT {
+			request.Additional = append(request.Additional, record)
+			continue
+		}
+		if request.EDNS != nil {
+			return odintypes.DNSRequest{}, fmt.Errorf("more than one OPT record in additional section")
+		}
+		request.EDNS, err = odintypes.EDNSFromRecord(record)
+		if err != nil {
+			return odintypes.DNSRequest{}, fmt.Errorf("error parsing OPT record: %w", err)
+		}
+	}
+
 	return request, nil
 }
 
+func parseRecordSection(buffer []byte, offset int, count int, section string) ([]*odintypes.DNSRecord, int, error) {
+	var records []*odintypes.DNSRecord
+	for i := range count {
+		record, newOffset, err := ParseResourceRecord(buffer, offset)
+		if err != nil {
+			return nil, offset, fmt.Errorf("error parsing %s record %d: %w", section, i+1, err)
+		}
+		record.RData, err = unpackRData(buffer, newOffset-len(record.RData), record)
+		if err != nil {
+			return nil, offset, fmt.Errorf("error parsing RData of %s record %d: %w", section, i+1, err)
+		}
+		offset = newOffset
+		records = append(records, record)
+	}
+	return records, offset, nil
+}
+
+// unpackRData converts the RData found at offset into the form records are kept in
+// everywhere else, the form packRData expects. Names inside it are expanded, which
+// needs the whole message because they may be compressed. Types without names are
+// kept as found on the wire.
+func unpackRData(buffer []byte, offset int, record *odintypes.DNSRecord) ([]byte, error) {
+	// dynamic updates match whole RRsets with records that carry no RData
+	if len(record.RData) == 0 {
+		return record.RData, nil
+	}
+	end := offset + len(record.RData)
+
+	switch record.Type {
+	case odintypes.TYPE_CNAME, odintypes.TYPE_NS, odintypes.TYPE_PTR, odintypes.TYPE_DNAME:
+		name, next, err := util.ParseDomainName(buffer, offset)
+		if err != nil || next > end {
+			return nil, fmt.Errorf("invalid domain name in RData")
+		}
+		return []byte(rDataName(name)), nil
+
+	case odintypes.TYPE_MX, odintypes.TYPE_SRV:
+		fixed := 2
+		if record.Type == odintypes.TYPE_SRV {
+			fixed = 6
+		}
+		if len(record.RData) <= fixed {
+			return nil, fmt.Errorf("RData too short: %d bytes", len(record.RData))
+		}
+		name, next, err := util.ParseDomainName(buffer, offset+fixed)
+		if err != nil || next > end {
+			return nil, fmt.Errorf("invalid domain name in RData")
+		}
+		return append(append([]byte{}, record.RData[:fixed]...), rDataName(name)...), nil
+
+	case odintypes.TYPE_SOA:
+		mname, next, err := util.ParseDomainName(buffer, offset)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SOA MNAME: %w", err)
+		}
+		rname, next, err := util.ParseDomainName(buffer, next)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SOA RNAME: %w", err)
+		}
+		if next+20 != end {
+			return nil, fmt.Errorf("invalid SOA RData length")
+		}
+		soa := odintypes.SOAData{
+			MName:   rDataName(mname),
+			RName:   rDataName(rname),
+			Serial:  binary.BigEndian.Uint32(buffer[next:]),
+			Refresh: binary.BigEndian.Uint32(buffer[next+4:]),
+			Retry:   binary.BigEndian.Uint32(buffer[next+8:]),
+			Expire:  binary.BigEndian.Uint32(buffer[next+12:]),
+			Minimum: binary.BigEndian.Uint32(buffer[next+16:]),
+		}
+		return soa.Pack()
+
+	default:
+		return record.RData, nil
+	}
+}
+
+// rDataName spells the root name, which ParseDomainName returns empty, as ".".
+func rDataName(name string) string {
+	if name == "" {
+		return "."
+	}
+	return name
+}
+
+// ParseResourceRecord reads one resource record starting at offset. The RData is
+// returned as found on the wire, compressed names inside it are not expanded.
+// parseRecordSection takes care of that.
+func ParseResourceRecord(buffer []byte, offset int) (*odintypes.DNSRecord, int, error) {
+	name, newOffset, err := util.ParseDomainName(buffer, offset)
+	if err != nil {
+		return nil, offset, fmt.Errorf("error parsing record name: %w", err)
+	}
+
+	if newOffset+10 > len(buffer) {
+		return nil, offset, fmt.Errorf("buffer too short for record type, class, ttl and rdlength")
+	}
+
+	record := &odintypes.DNSRecord{Name: name}
+	record.Type = binary.BigEndian.Uint16(buffer[newOffset : newOffset+2])
+	record.Class = binary.BigEndian.Uint16(buffer[newOffset+2 : newOffset+4])
+	record.TTL = binary.BigEndian.Uint32(buffer[newOffset+4 : newOffset+8])
+	rdLength := int(binary.BigEndian.Uint16(buffer[newOffset+8 : newOffset+10]))
+	newOffset += 10
+
+	if newOffset+rdLength > len(buffer) {
+		return nil, offset, fmt.Errorf("buffer too short for RData of %d bytes", rdLength)
+	}
+	record.RData = buffer[newOffset : newOffset+rdLength]
+	newOffset += rdLength
+
+	return record, newOffset, nil
+}
+
 func ParseHeaderSection(headerSection [12]byte) (odintypes.DNSHeader, error) {
 	var hsection odintypes.DNSHeader
 
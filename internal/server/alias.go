@@ -0,0 +1,146 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Unfield/Odin-DNS/pkg/odintypes"
+)
+
+// AliasResolver looks up the addresses of ALIAS targets that lie outside our
+// zones. The server uses an upstream resolver by default, tests can swap in a stub.
+type AliasResolver interface {
+	LookupAddresses(target string, qtype uint16, class uint16) ([]*odintypes.DNSRecord, error)
+}
+
+// upstreamAliasResolver asks a recursive resolver, retrying over TCP when the
+// UDP response was truncated.
+type upstreamAliasResolver struct {
+	address string
+	timeout time.Duration
+}
+
+func NewUpstreamAliasResolver(address string, timeout time.Duration) AliasResolver {
+	return &upstreamAliasResolver{address: address, timeout: timeout}
+}
+
+func (r *upstreamAliasResolver) LookupAddresses(target string, qtype uint16, class uint16) ([]*odintypes.DNSRecord, error) {
+	query := newQuery(target, qtype, class)
+	query.Header.Flags.RD = true
+
+	response, err := exchange("udp", r.address, query, r.timeout)
+	if err == nil && response.Header.Flags.TC {
+		response, err = exchange("tcp", r.address, query, r.timeout)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch response.Header.Flags.RCode {
+	case odintypes.RCODE_NOERROR, odintypes.RCODE_NXDOMAIN:
+	default:
+		return nil, fmt.Errorf("upstream %s answered %s with RCODE %d", r.address, target, response.Header.Flags.RCode)
+	}
+
+	// the answer may start with a CNAME chain, only the final addresses matter
+	var records []*odintypes.DNSRecord
+	for _, record := range response.Answers {
+		if record.Type == qtype && record.Class == class {
+			records = append(records, record)
+		}
+	}
+	return records, nil
+}
+
+// resolveAlias returns the A or AAAA records an ALIAS at qname points to, renamed
+// to qname. Targets inside our zones are followed through CNAMEs and further
+// ALIASes, everything else goes to the alias resolver with results cached in Redis.
+func (s *Server) resolveAlias(alias *odintypes.DNSRecord, qname string, qtype uint16, class uint16) ([]*odintypes.DNSRecord, error) {
+	target := strings.TrimSuffix(odintypes.FormatDomainName_RData(alias.RData), ".")
+	visited := map[string]bool{strings.ToLower(qname): true}
+
+	for hops := 0; hops < maxCNAMEChainLength; hops++ {
+		if visited[strings.ToLower(target)] {
+			s.logger.Warn("ALIAS loop detected", "name", qname, "target", target)
+			return nil, nil
+		}
+		visited[strings.ToLower(target)] = true
+
+		zone, err := s.cacheDriver.FindZoneForName(target)
+		if err != nil {
+			return nil, err
+		}
+		if zone == nil {
+			records, err := s.lookupExternalAlias(target, qtype, class)
+			if err != nil {
+				return nil, err
+			}
+			return aliasAnswer(records, alias, qname), nil
+		}
+
+		records, _, err := s.cacheDriver.LookupRecordForDNSQuery(zone.ID, target, qtype, class)
+		if err != nil {
+			return nil, err
+		}
+		if len(records) > 0 {
+			return aliasAnswer(records, alias, qname), nil
+		}
+
+		next := ""
+		for _, redirectType := range []uint16{odintypes.TYPE_CNAME, odintypes.TYPE_ALIAS} {
+			redirects, _, err := s.cacheDriver.LookupRecordForDNSQuery(zone.ID, target, redirectType, class)
+			if err != nil {
+				return nil, err
+			}
+			if len(redirects) > 0 {
+				next = strings.TrimSuffix(odintypes.FormatDomainName_RData(redirects[0].RData), ".")
+				break
+			}
+		}
+		if next == "" {
+			return nil, nil
+		}
+		target = next
+	}
+
+	s.logger.Warn("ALIAS chain too long, not following further", "name", qname, "target", target)
+	return nil, nil
+}
+
+// lookupExternalAlias answers from the Redis cache and asks the alias resolver
+// on a miss. Without a configured resolver external targets stay unresolved.
+func (s *Server) lookupExternalAlias(target string, qtype uint16, class uint16) ([]*odintypes.DNSRecord, error) {
+	records, found, err := s.cacheDriver.GetAliasTarget(target, qtype, class)
+	if err != nil {
+		s.logger.Warn("Failed to read ALIAS target from cache", "target", target, "error", err)
+	}
+	if found {
+		return records, nil
+	}
+
+	if s.aliasResolver == nil {
+		s.logger.Warn("ALIAS target outside our zones but no upstream is configured", "target", target)
+		return nil, nil
+	}
+
+	records, err = s.aliasResolver.LookupAddresses(target, qtype, class)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve ALIAS target %s: %w", target, err)
+	}
+	s.cacheDriver.CacheAliasTarget(target, qtype, class, records)
+	return records, nil
+}
+
+// aliasAnswer renames the target records to qname. The TTL is the target's TTL,
+// capped by the TTL of the ALIAS record itself.
+func aliasAnswer(records []*odintypes.DNSRecord, alias *odintypes.DNSRecord, qname string) []*odintypes.DNSRecord {
+	answer := make([]*odintypes.DNSRecord, 0, len(records))
+	for _, record := range records {
+		synthesized := *record
+		synthesized.Name = qname
+		synthesized.TTL = min(record.TTL, alias.TTL)
+		answer = append(answer, &synthesized)
+	}
+	return answer
+}
@@ -0,0 +1,138 @@
+package server
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Unfield/Odin-DNS/internal/types"
+	"github.com/Unfield/Odin-DNS/pkg/odintypes"
+)
+
+// stubAliasResolver answers ALIAS targets outside our zones from a fixed table
+// and counts how often it was asked.
+type stubAliasResolver struct {
+	mu        sync.Mutex
+	addresses map[string][]*odintypes.DNSRecord
+	calls     map[string]int
+}
+
+func (r *stubAliasResolver) LookupAddresses(target string, qtype uint16, class uint16) ([]*odintypes.DNSRecord, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls[strings.ToLower(target)]++
+
+	var records []*odintypes.DNSRecord
+	for _, record := range r.addresses[strings.ToLower(target)] {
+		if record.Type == qtype && record.Class == class {
+			records = append(records, record)
+		}
+	}
+	return records, nil
+}
+
+func (r *stubAliasResolver) callsFor(target string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.calls[target]
+}
+
+func newAliasTestServer(t *testing.T) (*Server, *stubAliasResolver, func(time.Duration)) {
+	t.Helper()
+
+	store := &memStore{}
+	store.addZone(types.DBZone{ID: "zone-example", Name: "example.com", Kind: types.ZoneKindPrimary})
+	store.addRecord("zone-example", "example.com", "SOA", 3600, "ns1.example.com hostmaster.example.com 1 3600 900 604800 300")
+	store.addRecord("zone-example", "example.com", "ALIAS", 300, "www.example.com")
+	store.addRecord("zone-example", "www.example.com", "A", 600, "192.0.2.10")
+	store.addRecord("zone-example", "cdn.example.com", "ALIAS", 3600, "edge.cdn.example.net")
+	store.addRecord("zone-example", "gone.example.com", "ALIAS", 3600, "missing.example.net")
+
+	edge, err := odintypes.ParseA_RData("198.51.100.7")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resolver := &stubAliasResolver{
+		addresses: map[string][]*odintypes.DNSRecord{
+			"edge.cdn.example.net": {{Name: "edge.cdn.example.net", Type: odintypes.TYPE_A, Class: odintypes.CLASS_IN, TTL: 120, RData: edge}},
+		},
+		calls: map[string]int{},
+	}
+
+	server, cache := newTestServer(t, store)
+	server.aliasResolver = resolver
+	return server, resolver, cache.FastForward
+}
+
+func resolveA(t *testing.T, server *Server, name string) []*odintypes.DNSRecord {
+	t.Helper()
+
+	result, err := server.resolve(odintypes.DNSQuestion{Name: name, Type: odintypes.TYPE_A, Class: odintypes.CLASS_IN}, false)
+	if err != nil {
+		t.Fatalf("resolve %s: %v", name, err)
+	}
+	if result.rcode != odintypes.RCODE_NOERROR {
+		t.Fatalf("resolve %s: RCODE %d, want NOERROR", name, result.rcode)
+	}
+	return result.answers
+}
+
+func checkAddresses(t *testing.T, answers []*odintypes.DNSRecord, name string, ttl uint32, addresses ...string) {
+	t.Helper()
+
+	if len(answers) != len(addresses) {
+		t.Fatalf("got %d answers for %s, want %d", len(answers), name, len(addresses))
+	}
+	for i, record := range answers {
+		if record.Name != name || record.Type != odintypes.TYPE_A || record.TTL != ttl {
+			t.Errorf("answer %d is %s %s TTL %d, want %s A TTL %d", i, record.Name, odintypes.TypeToString(record.Type), record.TTL, name, ttl)
+		}
+		if got := odintypes.FormatA_RData(record.RData); got != addresses[i] {
+			t.Errorf("answer %d has address %s, want %s", i, got, addresses[i])
+		}
+	}
+}
+
+func TestAliasInZoneTarget(t *testing.T) {
+	server, resolver, _ := newAliasTestServer(t)
+
+	// the TTL is capped by the ALIAS record, 300 instead of the target's 600
+	checkAddresses(t, resolveA(t, server, "example.com"), "example.com", 300, "192.0.2.10")
+
+	if calls := resolver.callsFor("www.example.com"); calls != 0 {
+		t.Errorf("in-zone target went to the upstream %d times", calls)
+	}
+}
+
+func TestAliasUpstreamTarget(t *testing.T) {
+	server, resolver, fastForward := newAliasTestServer(t)
+
+	checkAddresses(t, resolveA(t, server, "cdn.example.com"), "cdn.example.com", 120, "198.51.100.7")
+	checkAddresses(t, resolveA(t, server, "cdn.example.com"), "cdn.example.com", 120, "198.51.100.7")
+	if calls := resolver.callsFor("edge.cdn.example.net"); calls != 1 {
+		t.Fatalf("upstream asked %d times within the target TTL, want 1", calls)
+	}
+
+	fastForward(121 * time.Second)
+	checkAddresses(t, resolveA(t, server, "cdn.example.com"), "cdn.example.com", 120, "198.51.100.7")
+	if calls := resolver.callsFor("edge.cdn.example.net"); calls != 2 {
+		t.Fatalf("upstream asked %d times after the target TTL, want 2", calls)
+	}
+}
+
+func TestAliasNegativeCache(t *testing.T) {
+	server, resolver, fastForward := newAliasTestServer(t)
+
+	checkAddresses(t, resolveA(t, server, "gone.example.com"), "gone.example.com", 0)
+	checkAddresses(t, resolveA(t, server, "gone.example.com"), "gone.example.com", 0)
+	if calls := resolver.callsFor("missing.example.net"); calls != 1 {
+		t.Fatalf("upstream asked %d times for a cached missing target, want 1", calls)
+	}
+
+	fastForward(time.Minute + time.Second)
+	checkAddresses(t, resolveA(t, server, "gone.example.com"), "gone.example.com", 0)
+	if calls := resolver.callsFor("missing.example.net"); calls != 2 {
+		t.Fatalf("upstream asked %d times after the negative TTL, want 2", calls)
+	}
+}
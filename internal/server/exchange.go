@@ -0,0 +1,147 @@
+package server
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"time"
+
+	"github.com/Unfield/Odin-DNS/internal/parser"
+	"github.com/Unfield/Odin-DNS/pkg/odintypes"
+)
+
+// newQuery builds a query message for a single question with a random ID.
+func newQuery(name string, qtype uint16, class uint16) *odintypes.DNSRequest {
+	return &odintypes.DNSRequest{
+		Header: odintypes.DNSHeader{
+			ID: uint16(rand.UintN(0x10000)),
+		},
+		Questions: []odintypes.DNSQuestion{{Name: name, Type: qtype, Class: class}},
+	}
+}
+
+// exchange sends request to address over network ("udp" or "tcp") and waits for
+// the matching response. Responses with a different ID are rejected.
+func exchange(network string, address string, request *odintypes.DNSRequest, timeout time.Duration) (*odintypes.DNSRequest, error) {
+	message, err := parser.PackResponse(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pack query: %w", err)
+	}
+
+	conn, err := net.DialTimeout(network, address, timeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", address, err)
+	}
+	defer conn.Close()
+	if err := conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		return nil, err
+	}
+
+	if err := writeMessage(conn, network, message); err != nil {
+		return nil, err
+	}
+	buffer, err := readMessage(conn, network)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := parser.ParseRequest(buffer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse response from %s: %w", address, err)
+	}
+	if response.Header.ID != request.Header.ID {
+		return nil, fmt.Errorf("response from %s has ID %d, expected %d", address, response.Header.ID, request.Header.ID)
+	}
+	return &response, nil
+}
+
+// receiveTransfer requests a zone transfer over TCP and reads the answer records
+// of all response messages until complete reports that the last one arrived.
+// The timeout applies to each message, large zones may take longer in total.
+func receiveTransfer(address string, request *odintypes.DNSRequest, timeout time.Duration, complete func([]*odintypes.DNSRecord) (bool, error)) ([]*odintypes.DNSRecord, error) {
+	message, err := parser.PackResponse(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pack transfer query: %w", err)
+	}
+
+	conn, err := net.DialTimeout("tcp", address, timeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", address, err)
+	}
+	defer conn.Close()
+	if err := conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		return nil, err
+	}
+	if err := writeMessage(conn, "tcp", message); err != nil {
+		return nil, err
+	}
+
+	var records []*odintypes.DNSRecord
+	for {
+		if err := conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+			return nil, err
+		}
+		buffer, err := readMessage(conn, "tcp")
+		if err != nil {
+			return nil, err
+		}
+
+		response, err := parser.ParseRequest(buffer)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse transfer message from %s: %w", address, err)
+		}
+		if response.Header.ID != request.Header.ID {
+			return nil, fmt.Errorf("transfer message from %s has ID %d, expected %d", address, response.Header.ID, request.Header.ID)
+		}
+		if response.Header.Flags.RCode != odintypes.RCODE_NOERROR {
+			return nil, fmt.Errorf("%s answered the transfer with RCODE %d", address, response.Header.Flags.RCode)
+		}
+
+		records = append(records, response.Answers...)
+		done, err := complete(records)
+		if err != nil {
+			return nil, err
+		}
+		if done {
+			return records, nil
+		}
+	}
+}
+
+// writeMessage sends one DNS message, prefixed with its length on TCP (RFC 1035 4.2.2).
+func writeMessage(conn net.Conn, network string, message []byte) error {
+	if network == "tcp" {
+		framed := make([]byte, 2+len(message))
+		binary.BigEndian.PutUint16(framed, uint16(len(message)))
+		copy(framed[2:], message)
+		message = framed
+	}
+	if _, err := conn.Write(message); err != nil {
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+	return nil
+}
+
+// readMessage reads one DNS message from conn.
+func readMessage(conn net.Conn, network string) ([]byte, error) {
+	if network != "tcp" {
+		buffer := make([]byte, parser.MaxTCPMessageSize)
+		n, err := conn.Read(buffer)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read response: %w", err)
+		}
+		return buffer[:n], nil
+	}
+
+	var length [2]byte
+	if _, err := io.ReadFull(conn, length[:]); err != nil {
+		return nil, fmt.Errorf("failed to read message length: %w", err)
+	}
+	buffer := make([]byte, binary.BigEndian.Uint16(length[:]))
+	if _, err := io.ReadFull(conn, buffer); err != nil {
+		return nil, fmt.Errorf("failed to read message: %w", err)
+	}
+	return buffer, nil
+}
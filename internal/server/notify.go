@@ -0,0 +1,172 @@
+package server
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/Unfield/Odin-DNS/internal/types"
+	"github.com/Unfield/Odin-DNS/pkg/odintypes"
+)
+
+// watchZoneChanges notifies the secondaries of every zone changed through the
+// shared cache, whichever process made the change.
+func (s *Server) watchZoneChanges() {
+	for zoneID := range s.cacheDriver.SubscribeZoneChanges() {
+		s.notifySecondaries(zoneID)
+	}
+}
+
+// notifySecondaries sends a NOTIFY (RFC 1996) for the zone's current SOA to each
+// of its secondaries in the background.
+func (s *Server) notifySecondaries(zoneID string) {
+	secondaries, err := s.cacheDriver.GetZoneSecondaries(zoneID)
+	if err != nil {
+		s.logger.Error("Failed to load zone secondaries", "zone_id", zoneID, "error", err)
+		return
+	}
+	if len(secondaries) == 0 {
+		return
+	}
+
+	zone, err := s.cacheDriver.GetZone(zoneID)
+	if err != nil || zone == nil {
+		s.logger.Error("Failed to load changed zone", "zone_id", zoneID, "error", err)
+		return
+	}
+	dbSOA, err := s.cacheDriver.GetZoneSOA(zoneID)
+	if err != nil || dbSOA == nil {
+		s.logger.Error("Failed to load SOA of changed zone", "zone", zone.Name, "error", err)
+		return
+	}
+	soa, err := wireRecord(*dbSOA)
+	if err != nil {
+		s.logger.Error("Invalid SOA record", "zone", zone.Name, "error", err)
+		return
+	}
+
+	for _, secondary := range secondaries {
+		go s.sendNotify(zone, secondary.Address, soa)
+	}
+}
+
+// sendNotify retries a NOTIFY with a doubling interval until the secondary
+// acknowledges it or NOTIFY_RETRIES is exhausted. A newer NOTIFY for the same
+// zone and secondary cancels the retries of an older one.
+func (s *Server) sendNotify(zone *types.DBZone, address string, soa *odintypes.DNSRecord) {
+	key := zone.ID + "|" + address
+	cancel := make(chan struct{})
+
+	s.notifyMu.Lock()
+	if previous, ok := s.notifyInFlight[key]; ok {
+		close(previous)
+	}
+	s.notifyInFlight[key] = cancel
+	s.notifyMu.Unlock()
+
+	defer func() {
+		s.notifyMu.Lock()
+		if s.notifyInFlight[key] == cancel {
+			delete(s.notifyInFlight, key)
+		}
+		s.notifyMu.Unlock()
+	}()
+
+	interval := s.config.NOTIFY_RETRY_INTERVAL
+	for attempt := 0; ; attempt++ {
+		err := s.notify(zone, address, soa)
+		if err == nil {
+			s.logger.Info("NOTIFY acknowledged", "zone", zone.Name, "secondary", address)
+			return
+		}
+		if attempt >= s.config.NOTIFY_RETRIES {
+			s.logger.Warn("Giving up on NOTIFY", "zone", zone.Name, "secondary", address, "attempts", attempt+1, "error", err)
+			return
+		}
+		s.logger.Debug("NOTIFY not acknowledged, retrying", "zone", zone.Name, "secondary", address, "in", interval, "error", err)
+
+		select {
+		case <-cancel:
+			return
+		case <-time.After(interval):
+		}
+		interval *= 2
+	}
+}
+
+// notify sends a single NOTIFY over UDP and checks the acknowledgement. The
+// current SOA is included as a hint for the secondary (RFC 1996 3.7).
+func (s *Server) notify(zone *types.DBZone, address string, soa *odintypes.DNSRecord) error {
+	request := newQuery(zone.Name, odintypes.TYPE_SOA, odintypes.CLASS_IN)
+	request.Header.Flags.Opcode = odintypes.OPCODE_NOTIFY
+	request.Header.Flags.AA = true
+	request.Answers = []*odintypes.DNSRecord{soa}
+
+	response, err := exchange("udp", address, request, s.config.NOTIFY_TIMEOUT)
+	if err != nil {
+		return err
+	}
+	if !response.Header.Flags.QR || response.Header.Flags.Opcode != odintypes.OPCODE_NOTIFY {
+		return fmt.Errorf("%s did not answer with a NOTIFY response", address)
+	}
+	if response.Header.Flags.RCode != odintypes.RCODE_NOERROR {
+		return fmt.Errorf("%s answered NOTIFY with RCODE %d", address, response.Header.Flags.RCode)
+	}
+	return nil
+}
+
+// handleNotify acknowledges a NOTIFY we received (RFC 1996 4.7). Only NOTIFYs
+// for the apex of a zone we serve are accepted. For a secondary zone the sender
+// must be one of its primaries and the zone is refreshed right away, a primary
+// zone needs no further action. It returns the RCODE for the metrics.
+func (s *Server) handleNotify(w responseWriter, response *odintypes.DNSRequest, question odintypes.DNSQuestion, maxSize int) (uint8, error) {
+	rcode, err := s.acceptNotify(w, question)
+	response.Header.Flags.RCode = rcode
+	response.Header.Flags.AA = rcode == odintypes.RCODE_NOERROR
+
+	if writeErr := w.WriteResponse(response, maxSize); writeErr != nil && err == nil {
+		err = writeErr
+	}
+	return rcode, err
+}
+
+func (s *Server) acceptNotify(w responseWriter, question odintypes.DNSQuestion) (uint8, error) {
+	if question.Type != odintypes.TYPE_SOA {
+		return odintypes.RCODE_FORMERR, nil
+	}
+
+	zone, err := s.cacheDriver.FindZoneForName(question.Name)
+	if err != nil {
+		return odintypes.RCODE_SERVFAIL, err
+	}
+	if zone == nil || !strings.EqualFold(zone.Name, strings.TrimSuffix(question.Name, ".")) {
+		return odintypes.RCODE_NOTAUTH, nil
+	}
+
+	s.logger.Info("NOTIFY received", "zone", zone.Name, "client", w.RemoteAddr().String())
+	if zone.IsSecondary() {
+		if !fromPrimary(zone, w.RemoteAddr()) {
+			return odintypes.RCODE_REFUSED, nil
+		}
+		s.requestRefresh(zone.ID)
+	}
+	return odintypes.RCODE_NOERROR, nil
+}
+
+// fromPrimary reports whether addr is the IP of one of the zone's primaries. The
+// port is ignored, NOTIFYs are usually sent from an ephemeral one.
+func fromPrimary(zone *types.DBZone, addr net.Addr) bool {
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return false
+	}
+	ip := net.ParseIP(host)
+	for _, primary := range zone.PrimaryAddresses() {
+		primaryHost, _, err := net.SplitHostPort(primary)
+		if err == nil && ip.Equal(net.ParseIP(primaryHost)) {
+			return true
+		}
+	}
+	return false
+}
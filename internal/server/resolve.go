@@ -0,0 +1,445 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Unfield/Odin-DNS/internal/types"
+	"github.com/Unfield/Odin-DNS/internal/util"
+	"github.com/Unfield/Odin-DNS/pkg/odintypes"
+)
+
+// answer collects everything the lookup produced for one question.
+type answer struct {
+	rcode         uint8
+	authoritative bool
+	answers       []*odintypes.DNSRecord
+	authority     []*odintypes.DNSRecord
+	additional    []*odintypes.DNSRecord
+	cacheHit      uint8
+}
+
+// maxCNAMEChainLength bounds how many CNAMEs are followed for one question.
+const maxCNAMEChainLength = 8
+
+// resolve answers a question from authoritative data of the zone closest enclosing
+// the name, questions for names outside all our zones are REFUSED. Names at or below a zone
+// cut are answered with a referral to the delegated servers. A missing RRset results in
+// NODATA when the owner name exists and in NXDOMAIN otherwise, both carrying the
+// zone's SOA in the authority section so resolvers can cache them (RFC 2308).
+// Names that do not exist are answered from a matching wildcard (RFC 4592).
+// CNAMEs are added to the answer and followed as long as the target lies in a
+// zone we serve, the final RCODE describes the last name of the chain (RFC 6604).
+// Names below a DNAME are redirected by a CNAME synthesized from it (RFC 6672).
+// ANY queries get every RRset of the name only if fullANY is set (RFC 8482).
+func (s *Server) resolve(question odintypes.DNSQuestion, fullANY bool) (*answer, error) {
+	result := &answer{
+		rcode:         odintypes.RCODE_NOERROR,
+		authoritative: true,
+	}
+
+	qname := question.Name
+	visited := map[string]bool{strings.ToLower(qname): true}
+
+	for chainLength := 0; ; chainLength++ {
+		zone, err := s.cacheDriver.FindZoneForName(qname)
+		if err != nil {
+			return nil, err
+		}
+		if zone == nil {
+			if chainLength == 0 {
+				result.rcode = odintypes.RCODE_REFUSED
+				result.authoritative = false
+			}
+			// a CNAME target outside our zones is left to the resolver
+			return result, nil
+		}
+		if zone.Expired(time.Now()) {
+			// RFC 1034 4.3.5: an expired secondary zone must not be answered from
+			if chainLength == 0 {
+				return nil, fmt.Errorf("secondary zone %s has expired", zone.Name)
+			}
+			return result, nil
+		}
+
+		cut, err := s.findZoneCut(zone, qname, question.Class)
+		if err != nil {
+			return nil, err
+		}
+		dname, err := s.findDNAME(zone, qname, question.Class)
+		if err != nil {
+			return nil, err
+		}
+
+		var cname *odintypes.DNSRecord
+		if dname != nil && (cut == nil || isBelow(cut[0].Name, dname.Name)) {
+			// a DNAME above the zone cut hides the delegation
+			result.answers = append(result.answers, dname)
+			cname = synthesizeCNAME(qname, dname)
+			if cname == nil {
+				result.rcode = odintypes.RCODE_YXDOMAIN
+				return result, nil
+			}
+		} else {
+			if cut != nil {
+				if chainLength > 0 {
+					// the CNAME target was delegated away, the resolver follows it from here
+					return result, nil
+				}
+				return result, s.referral(zone, result, cut, question.Class)
+			}
+
+			var rrset []*odintypes.DNSRecord
+			var cacheHit uint8
+			rrset, cname, cacheHit, err = s.lookupOwner(zone, qname, qname, question.Type, question.Class, fullANY)
+			if err != nil {
+				return nil, err
+			}
+			if chainLength == 0 {
+				result.cacheHit = cacheHit
+			}
+
+			if len(rrset) == 0 && cname == nil {
+				exists, err := s.cacheDriver.NameExists(zone.ID, qname, question.Class)
+				if err != nil {
+					return nil, err
+				}
+				if exists {
+					return result, s.negativeAnswer(zone, result, question.Class)
+				}
+
+				wildcard, err := s.findWildcard(zone, qname, question.Class)
+				if err != nil {
+					return nil, err
+				}
+				if wildcard == "" {
+					result.rcode = odintypes.RCODE_NXDOMAIN
+					return result, s.negativeAnswer(zone, result, question.Class)
+				}
+
+				rrset, cname, _, err = s.lookupOwner(zone, wildcard, qname, question.Type, question.Class, fullANY)
+				if err != nil {
+					return nil, err
+				}
+			}
+
+			if len(rrset) > 0 {
+				result.answers = append(result.answers, rrset...)
+				return result, s.addTargetAddresses(zone, result, rrset, zone.Name, question.Class)
+			}
+
+			if cname == nil {
+				return result, s.negativeAnswer(zone, result, question.Class)
+			}
+		}
+
+		result.answers = append(result.answers, cname)
+
+		target := odintypes.FormatDomainName_RData(cname.RData)
+		if visited[strings.ToLower(target)] {
+			s.logger.Warn("CNAME loop detected", "name", question.Name, "target", target)
+			return result, nil
+		}
+		if chainLength+1 >= maxCNAMEChainLength {
+			s.logger.Warn("CNAME chain too long, not following further", "name", question.Name, "target", target)
+			return result, nil
+		}
+
+		visited[strings.ToLower(target)] = true
+		qname = target
+	}
+}
+
+// findZoneCut returns the NS RRset of the highest delegation between the zone apex
+// and qname. Everything below such a cut belongs to the child zone, the records we
+// hold there only serve as glue.
+func (s *Server) findZoneCut(zone *types.DBZone, qname string, class uint16) ([]*odintypes.DNSRecord, error) {
+	var candidates []string
+	for candidate := qname; candidate != "" && !strings.EqualFold(candidate, zone.Name); candidate = util.ParentDomain(candidate) {
+		candidates = append(candidates, candidate)
+	}
+
+	for i := len(candidates) - 1; i >= 0; i-- {
+		nsRecords, _, err := s.cacheDriver.LookupRecordForDNSQuery(zone.ID, candidates[i], odintypes.TYPE_NS, class)
+		if err != nil {
+			return nil, err
+		}
+		if len(nsRecords) > 0 {
+			return nsRecords, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// findDNAME returns the DNAME of the highest proper ancestor of qname inside its
+// zone. The owner of a DNAME itself is not redirected, only the names below it.
+func (s *Server) findDNAME(zone *types.DBZone, qname string, class uint16) (*odintypes.DNSRecord, error) {
+	var candidates []string
+	for candidate := util.ParentDomain(qname); util.IsSubdomain(candidate, zone.Name); candidate = util.ParentDomain(candidate) {
+		candidates = append(candidates, candidate)
+		if strings.EqualFold(candidate, zone.Name) {
+			break
+		}
+	}
+
+	for i := len(candidates) - 1; i >= 0; i-- {
+		dnames, _, err := s.cacheDriver.LookupRecordForDNSQuery(zone.ID, candidates[i], odintypes.TYPE_DNAME, class)
+		if err != nil {
+			return nil, err
+		}
+		if len(dnames) > 0 {
+			return dnames[0], nil
+		}
+	}
+
+	return nil, nil
+}
+
+// synthesizeCNAME replaces the DNAME owner suffix of qname by the DNAME target
+// (RFC 6672 2.2). It returns nil if the resulting name would exceed 255 octets.
+func synthesizeCNAME(qname string, dname *odintypes.DNSRecord) *odintypes.DNSRecord {
+	prefix := qname[:len(qname)-len(dname.Name)]
+	target := strings.TrimSuffix(odintypes.FormatDomainName_RData(dname.RData), ".")
+	if target != "" {
+		target = prefix + target
+	} else {
+		target = strings.TrimSuffix(prefix, ".")
+	}
+
+	if _, err := odintypes.PackUncompressedName(target); err != nil {
+		return nil
+	}
+	return &odintypes.DNSRecord{
+		Name:  qname,
+		Type:  odintypes.TYPE_CNAME,
+		Class: dname.Class,
+		TTL:   dname.TTL,
+		RData: []byte(target),
+	}
+}
+
+// isBelow reports whether name lies strictly below parent.
+func isBelow(name string, parent string) bool {
+	return util.IsSubdomain(name, parent) && !strings.EqualFold(strings.TrimSuffix(name, "."), strings.TrimSuffix(parent, "."))
+}
+
+// referral points the client to the delegated name servers. It is not an
+// authoritative answer; addresses of name servers inside the delegated zone are
+// added as glue because the client could not resolve them otherwise.
+func (s *Server) referral(zone *types.DBZone, result *answer, cut []*odintypes.DNSRecord, class uint16) error {
+	result.authoritative = false
+	result.authority = append(result.authority, cut...)
+
+	return s.addTargetAddresses(zone, result, cut, cut[0].Name, class)
+}
+
+// addTargetAddresses looks up the A and AAAA records of all MX, NS and SRV targets
+// below bailiwick in one batch and appends them to the additional section, saving
+// the client the follow-up queries.
+func (s *Server) addTargetAddresses(zone *types.DBZone, result *answer, records []*odintypes.DNSRecord, bailiwick string, class uint16) error {
+	var targets []string
+	seen := map[string]bool{}
+	for _, record := range records {
+		target := strings.TrimSuffix(additionalTarget(record), ".")
+		if target == "" || seen[strings.ToLower(target)] || !util.IsSubdomain(target, bailiwick) {
+			continue
+		}
+		seen[strings.ToLower(target)] = true
+		targets = append(targets, target)
+	}
+
+	if len(targets) == 0 {
+		return nil
+	}
+
+	addresses, _, err := s.cacheDriver.LookupAddressRecords(zone.ID, targets, class)
+	if err != nil {
+		return err
+	}
+	result.additional = append(result.additional, addresses...)
+	return nil
+}
+
+// additionalTarget returns the host name whose addresses belong in the additional
+// section for record (RFC 1035 3.3.9, 3.3.11, RFC 2782), or "" for other types.
+func additionalTarget(record *odintypes.DNSRecord) string {
+	switch record.Type {
+	case odintypes.TYPE_NS:
+		return odintypes.FormatDomainName_RData(record.RData)
+	case odintypes.TYPE_MX:
+		if len(record.RData) > 2 {
+			return odintypes.FormatDomainName_RData(record.RData[2:])
+		}
+	case odintypes.TYPE_SRV:
+		if len(record.RData) > 6 {
+			return odintypes.FormatDomainName_RData(record.RData[6:])
+		}
+	}
+	return ""
+}
+
+// lookupOwner returns the RRset of the requested type stored at owner or, if there
+// is none, the CNAME stored there. A and AAAA queries fall back to flattening an
+// ALIAS at owner. Records are renamed to qname, which differs from owner when the
+// answer is synthesized from a wildcard.
+func (s *Server) lookupOwner(zone *types.DBZone, owner string, qname string, qtype uint16, class uint16, fullANY bool) ([]*odintypes.DNSRecord, *odintypes.DNSRecord, uint8, error) {
+	if qtype == odintypes.TYPE_ALIAS {
+		// ALIAS records are only an instruction to the server, clients never see them
+		return nil, nil, 0, nil
+	}
+	if qtype == odintypes.TYPE_ANY {
+		rrset, err := s.lookupANY(zone, owner, qname, class, fullANY)
+		return rrset, nil, 0, err
+	}
+
+	rrset, cacheHit, err := s.cacheDriver.LookupRecordForDNSQuery(zone.ID, owner, qtype, class)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	if len(rrset) > 0 {
+		return withOwnerName(rrset, owner, qname), nil, cacheHit, nil
+	}
+
+	if qtype == odintypes.TYPE_CNAME {
+		return nil, nil, cacheHit, nil
+	}
+
+	cnames, _, err := s.cacheDriver.LookupRecordForDNSQuery(zone.ID, owner, odintypes.TYPE_CNAME, class)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	if len(cnames) > 0 {
+		return nil, withOwnerName(cnames, owner, qname)[0], cacheHit, nil
+	}
+
+	if qtype == odintypes.TYPE_A || qtype == odintypes.TYPE_AAAA {
+		aliases, _, err := s.cacheDriver.LookupRecordForDNSQuery(zone.ID, owner, odintypes.TYPE_ALIAS, class)
+		if err != nil {
+			return nil, nil, 0, err
+		}
+		if len(aliases) > 0 {
+			rrset, err := s.resolveAlias(aliases[0], qname, qtype, class)
+			if err != nil {
+				return nil, nil, 0, err
+			}
+			return rrset, nil, cacheHit, nil
+		}
+	}
+
+	return nil, nil, cacheHit, nil
+}
+
+// anyHINFO is the RDATA of the HINFO RRset RFC 8482 4.2 answers ANY queries with.
+var anyHINFO, _ = odintypes.PackTXTStrings([]string{"RFC8482", ""})
+
+// anyHINFOTTL is the TTL of the synthesized HINFO record, which RFC 8482 4.2 leaves
+// to the responder.
+const anyHINFOTTL = 3600
+
+// lookupANY answers an ANY query for owner. Unless fullANY is set only a single
+// synthesized HINFO record is returned, which keeps the response small and useless
+// for amplification (RFC 8482 4.2); that only needs the cached existence check.
+// Otherwise every RRset at owner is returned, with an ALIAS replaced by the
+// addresses it resolves to.
+func (s *Server) lookupANY(zone *types.DBZone, owner string, qname string, class uint16, fullANY bool) ([]*odintypes.DNSRecord, error) {
+	if !fullANY {
+		exists, err := s.cacheDriver.OwnsRecords(zone.ID, owner, class)
+		if err != nil || !exists {
+			return nil, err
+		}
+		return []*odintypes.DNSRecord{{Name: qname, Type: odintypes.TYPE_HINFO, Class: class, TTL: anyHINFOTTL, RData: anyHINFO}}, nil
+	}
+
+	records, err := s.cacheDriver.LookupAllRecords(zone.ID, owner, class)
+	if err != nil || len(records) == 0 {
+		return nil, err
+	}
+
+	rrset := make([]*odintypes.DNSRecord, 0, len(records))
+	for _, record := range records {
+		if record.Type != odintypes.TYPE_ALIAS {
+			rrset = append(rrset, record)
+			continue
+		}
+		for _, qtype := range []uint16{odintypes.TYPE_A, odintypes.TYPE_AAAA} {
+			addresses, err := s.resolveAlias(record, owner, qtype, class)
+			if err != nil {
+				return nil, err
+			}
+			rrset = append(rrset, addresses...)
+		}
+	}
+	return withOwnerName(rrset, owner, qname), nil
+}
+
+func withOwnerName(rrset []*odintypes.DNSRecord, owner string, qname string) []*odintypes.DNSRecord {
+	if owner == qname {
+		return rrset
+	}
+	renamed := make([]*odintypes.DNSRecord, 0, len(rrset))
+	for _, record := range rrset {
+		synthesized := *record
+		synthesized.Name = qname
+		renamed = append(renamed, &synthesized)
+	}
+	return renamed
+}
+
+// findWildcard returns the source of synthesis for a name that does not exist:
+// the wildcard directly below its closest encloser, the deepest existing ancestor
+// inside the zone (RFC 4592 3.3.1). An empty string means no wildcard applies.
+func (s *Server) findWildcard(zone *types.DBZone, qname string, class uint16) (string, error) {
+	for candidate := util.ParentDomain(qname); util.IsSubdomain(candidate, zone.Name); candidate = util.ParentDomain(candidate) {
+		exists, err := s.cacheDriver.NameExists(zone.ID, candidate, class)
+		if err != nil {
+			return "", err
+		}
+		if !exists {
+			continue
+		}
+
+		wildcard := "*." + candidate
+		wildcardExists, err := s.cacheDriver.NameExists(zone.ID, wildcard, class)
+		if err != nil || !wildcardExists {
+			return "", err
+		}
+		return wildcard, nil
+	}
+
+	return "", nil
+}
+
+// negativeAnswer adds the SOA of zone to the authority section.
+func (s *Server) negativeAnswer(zone *types.DBZone, result *answer, class uint16) error {
+	soa, err := s.zoneSOA(zone, class)
+	if err != nil {
+		s.logger.Warn("Failed to look up SOA for negative answer", "zone", zone.Name, "error", err)
+		return nil
+	}
+	if soa != nil {
+		result.authority = append(result.authority, negativeSOA(soa))
+	}
+
+	return nil
+}
+
+// negativeSOA returns a copy of the SOA whose TTL is capped by its MINIMUM field,
+// which is how long resolvers may cache the negative answer (RFC 2308 3).
+func negativeSOA(soa *odintypes.DNSRecord) *odintypes.DNSRecord {
+	negative := *soa
+	if data, err := odintypes.UnpackSOAData(soa.RData); err == nil {
+		negative.TTL = min(soa.TTL, data.Minimum)
+	}
+	return &negative
+}
+
+// zoneSOA returns the SOA record at the apex of zone, or nil if it has none.
+func (s *Server) zoneSOA(zone *types.DBZone, class uint16) (*odintypes.DNSRecord, error) {
+	rrset, _, err := s.cacheDriver.LookupRecordForDNSQuery(zone.ID, zone.Name, odintypes.TYPE_SOA, class)
+	if err != nil || len(rrset) == 0 {
+		return nil, err
+	}
+	return rrset[0], nil
+}
@@ -0,0 +1,360 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Unfield/Odin-DNS/internal/types"
+	"github.com/Unfield/Odin-DNS/internal/util"
+	"github.com/Unfield/Odin-DNS/pkg/odintypes"
+	gonanoid "github.com/matoous/go-nanoid/v2"
+)
+
+// secondaryPollInterval is how often the refresh timers of secondary zones are checked.
+const secondaryPollInterval = 10 * time.Second
+
+// secondaryState tracks the refresh timer of one secondary zone.
+type secondaryState struct {
+	nextRefresh time.Time
+	running     bool
+	// refreshAgain is set by a NOTIFY that arrives during a refresh
+	refreshAgain bool
+}
+
+// runSecondaries keeps secondary zones in sync with their primaries. Each zone is
+// refreshed when its SOA refresh interval has passed, after the retry interval
+// when the last attempt failed, or right away when a primary sent a NOTIFY.
+func (s *Server) runSecondaries() {
+	ticker := time.NewTicker(secondaryPollInterval)
+	defer ticker.Stop()
+
+	for {
+		s.refreshDueZones()
+		select {
+		case <-ticker.C:
+		case <-s.refreshNow:
+		}
+	}
+}
+
+// requestRefresh schedules an immediate refresh of a secondary zone.
+func (s *Server) requestRefresh(zoneID string) {
+	s.secondaryMu.Lock()
+	state, ok := s.secondaryZones[zoneID]
+	if !ok {
+		state = &secondaryState{}
+		s.secondaryZones[zoneID] = state
+	}
+	if state.running {
+		state.refreshAgain = true
+	} else {
+		state.nextRefresh = time.Time{}
+	}
+	s.secondaryMu.Unlock()
+
+	select {
+	case s.refreshNow <- struct{}{}:
+	default:
+	}
+}
+
+func (s *Server) refreshDueZones() {
+	zones, err := s.cacheDriver.GetSecondaryZones()
+	if err != nil {
+		s.logger.Error("Failed to load secondary zones", "error", err)
+		return
+	}
+
+	now := time.Now()
+	known := make(map[string]bool, len(zones))
+
+	s.secondaryMu.Lock()
+	defer s.secondaryMu.Unlock()
+
+	for _, zone := range zones {
+		known[zone.ID] = true
+		state, ok := s.secondaryZones[zone.ID]
+		if !ok {
+			state = &secondaryState{}
+			s.secondaryZones[zone.ID] = state
+		}
+		if state.running || now.Before(state.nextRefresh) {
+			continue
+		}
+		state.running = true
+		go s.refreshZone(zone)
+	}
+
+	for id, state := range s.secondaryZones {
+		if !known[id] && !state.running {
+			delete(s.secondaryZones, id)
+		}
+	}
+}
+
+func (s *Server) refreshZone(zone types.DBZone) {
+	next, err := s.refreshSecondary(&zone)
+	if err != nil {
+		s.logger.Warn("Failed to refresh secondary zone", "zone", zone.Name, "retry_in", next, "error", err)
+	}
+
+	s.secondaryMu.Lock()
+	defer s.secondaryMu.Unlock()
+
+	state := s.secondaryZones[zone.ID]
+	state.running = false
+	state.nextRefresh = time.Now().Add(next)
+	if state.refreshAgain {
+		state.refreshAgain = false
+		state.nextRefresh = time.Time{}
+		select {
+		case s.refreshNow <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// refreshSecondary brings a secondary zone up to date with the first primary that
+// answers (RFC 1034 4.3.5). It returns the time until the next refresh, which is
+// the SOA retry interval when no primary could be reached.
+func (s *Server) refreshSecondary(zone *types.DBZone) (time.Duration, error) {
+	local, err := s.cacheDriver.GetZoneSOA(zone.ID)
+	if err != nil {
+		return time.Duration(s.config.SOA_RETRY) * time.Second, fmt.Errorf("failed to load local SOA: %w", err)
+	}
+
+	var localSOA *odintypes.SOAData
+	retry := time.Duration(s.config.SOA_RETRY) * time.Second
+	if local != nil {
+		if localSOA, err = odintypes.ParseSOAData(local.RData); err != nil {
+			return retry, fmt.Errorf("stored SOA record is invalid: %w", err)
+		}
+		retry = time.Duration(localSOA.Retry) * time.Second
+	}
+
+	primaries := zone.PrimaryAddresses()
+	if len(primaries) == 0 {
+		return retry, errors.New("zone has no primaries")
+	}
+
+	var lastErr error
+	for _, primary := range primaries {
+		refresh, err := s.refreshFromPrimary(zone, primary, local, localSOA)
+		if err == nil {
+			return refresh, nil
+		}
+		s.logger.Debug("Primary failed to refresh secondary zone", "zone", zone.Name, "primary", primary, "error", err)
+		lastErr = err
+	}
+	return retry, lastErr
+}
+
+// refreshFromPrimary compares the serial of the primary with ours and transfers the
+// zone when the primary is ahead. Either way the zone is valid for another SOA
+// expire interval afterwards.
+func (s *Server) refreshFromPrimary(zone *types.DBZone, primary string, local *types.DBRecord, localSOA *odintypes.SOAData) (time.Duration, error) {
+	remote, err := s.queryPrimarySOA(zone, primary)
+	if err != nil {
+		return 0, err
+	}
+
+	if localSOA == nil || odintypes.SOASerialLess(localSOA.Serial, remote.Serial) {
+		if err := s.transferFromPrimary(zone, primary, local, localSOA); err != nil {
+			return 0, err
+		}
+		s.logger.Info("Secondary zone transferred", "zone", zone.Name, "primary", primary, "serial", remote.Serial)
+	}
+
+	expiresAt := time.Now().UTC().Add(time.Duration(remote.Expire) * time.Second)
+	if err := s.cacheDriver.SetZoneExpiry(zone.ID, expiresAt); err != nil {
+		return 0, fmt.Errorf("failed to extend zone expiry: %w", err)
+	}
+	return time.Duration(remote.Refresh) * time.Second, nil
+}
+
+// queryPrimarySOA asks a primary for the zone SOA, over TCP if the UDP answer
+// was truncated.
+func (s *Server) queryPrimarySOA(zone *types.DBZone, primary string) (*odintypes.SOAData, error) {
+	request := newQuery(zone.Name, odintypes.TYPE_SOA, odintypes.CLASS_IN)
+	response, err := exchange("udp", primary, request, s.config.TRANSFER_TIMEOUT)
+	if err == nil && response.Header.Flags.TC {
+		response, err = exchange("tcp", primary, request, s.config.TRANSFER_TIMEOUT)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if response.Header.Flags.RCode != odintypes.RCODE_NOERROR || !response.Header.Flags.AA {
+		return nil, fmt.Errorf("%s is not authoritative for %s (RCODE %d)", primary, zone.Name, response.Header.Flags.RCode)
+	}
+
+	for _, record := range response.Answers {
+		if isZoneSOA(record, zone.Name) {
+			return odintypes.UnpackSOAData(record.RData)
+		}
+	}
+	return nil, fmt.Errorf("%s returned no SOA for %s", primary, zone.Name)
+}
+
+// transferFromPrimary pulls the zone with IXFR when we hold a copy already and
+// with AXFR otherwise. A primary may answer an IXFR with a full zone (RFC 1995 4).
+func (s *Server) transferFromPrimary(zone *types.DBZone, primary string, local *types.DBRecord, localSOA *odintypes.SOAData) error {
+	request := newQuery(zone.Name, odintypes.TYPE_AXFR, odintypes.CLASS_IN)
+	if local != nil {
+		soa, err := wireRecord(*local)
+		if err != nil {
+			return fmt.Errorf("stored SOA record is invalid: %w", err)
+		}
+		request = newQuery(zone.Name, odintypes.TYPE_IXFR, odintypes.CLASS_IN)
+		request.Authority = []*odintypes.DNSRecord{soa}
+	}
+
+	records, err := receiveTransfer(primary, request, s.config.TRANSFER_TIMEOUT, transferComplete(zone.Name, localSOA))
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case len(records) == 1:
+		return nil
+	case len(records) > 2 && isZoneSOA(records[1], zone.Name):
+		return s.applyIncrementalTransfer(zone, records)
+	default:
+		return s.applyFullTransfer(zone, records)
+	}
+}
+
+// transferComplete returns the check for receiveTransfer that the last message
+// of a transfer arrived. Full transfers end with the first SOA repeated, an
+// incremental one with the new SOA after an even number of SOAs for the changes
+// in between, and a client that is up to date only gets the SOA.
+func transferComplete(zoneName string, localSOA *odintypes.SOAData) func([]*odintypes.DNSRecord) (bool, error) {
+	return func(records []*odintypes.DNSRecord) (bool, error) {
+		if len(records) == 0 {
+			return false, nil
+		}
+		if !isZoneSOA(records[0], zoneName) {
+			return false, fmt.Errorf("transfer of %s does not start with its SOA", zoneName)
+		}
+		first, err := odintypes.UnpackSOAData(records[0].RData)
+		if err != nil {
+			return false, err
+		}
+		if len(records) == 1 {
+			return localSOA != nil && !odintypes.SOASerialLess(localSOA.Serial, first.Serial), nil
+		}
+
+		last := records[len(records)-1]
+		if !isZoneSOA(last, zoneName) {
+			return false, nil
+		}
+		lastSOA, err := odintypes.UnpackSOAData(last.RData)
+		if err != nil {
+			return false, err
+		}
+		if lastSOA.Serial != first.Serial {
+			return false, nil
+		}
+		if !isZoneSOA(records[1], zoneName) {
+			return true, nil
+		}
+
+		soas := 0
+		for _, record := range records[1:] {
+			if isZoneSOA(record, zoneName) {
+				soas++
+			}
+		}
+		return soas%2 == 1, nil
+	}
+}
+
+// applyFullTransfer replaces the zone with the records of an AXFR, the closing
+// SOA is left out.
+func (s *Server) applyFullTransfer(zone *types.DBZone, records []*odintypes.DNSRecord) error {
+	dbRecords, err := transferredRecords(zone, records[:len(records)-1])
+	if err != nil {
+		return err
+	}
+	return s.cacheDriver.ReplaceZoneRecords(zone.ID, dbRecords)
+}
+
+// applyIncrementalTransfer applies the changes of an IXFR one after the other.
+// Each starts with the old SOA followed by the deleted records, then the new SOA
+// followed by the added records (RFC 1995 4). Applying the SOAs like any other
+// record keeps the local serial at the last change applied, so a failure halfway
+// resumes from there on the next refresh.
+func (s *Server) applyIncrementalTransfer(zone *types.DBZone, records []*odintypes.DNSRecord) error {
+	type delta struct {
+		deleted []*odintypes.DNSRecord
+		added   []*odintypes.DNSRecord
+		adding  bool
+	}
+
+	var deltas []*delta
+	for _, record := range records[1 : len(records)-1] {
+		if isZoneSOA(record, zone.Name) {
+			if len(deltas) == 0 || deltas[len(deltas)-1].adding {
+				deltas = append(deltas, &delta{})
+			} else {
+				deltas[len(deltas)-1].adding = true
+			}
+		}
+		current := deltas[len(deltas)-1]
+		if current.adding {
+			current.added = append(current.added, record)
+		} else {
+			current.deleted = append(current.deleted, record)
+		}
+	}
+
+	for _, change := range deltas {
+		deleted, err := transferredRecords(zone, change.deleted)
+		if err != nil {
+			return err
+		}
+		added, err := transferredRecords(zone, change.added)
+		if err != nil {
+			return err
+		}
+		if err := s.cacheDriver.ApplyZoneDelta(zone.ID, deleted, added); err != nil {
+			return fmt.Errorf("failed to apply zone change: %w", err)
+		}
+	}
+	return nil
+}
+
+// transferredRecords converts transferred records into stored ones. Records
+// outside the zone are dropped, a primary has no business sending them.
+func transferredRecords(zone *types.DBZone, records []*odintypes.DNSRecord) ([]types.DBRecord, error) {
+	dbRecords := make([]types.DBRecord, 0, len(records))
+	for _, record := range records {
+		if !util.IsSubdomain(record.Name, zone.Name) {
+			continue
+		}
+		rData := util.ConvertRDataBytesToString(record.Type, record.RData)
+		if rData == "" {
+			return nil, fmt.Errorf("invalid %s record for %s in transfer", odintypes.TypeToString(record.Type), record.Name)
+		}
+		id, err := gonanoid.New()
+		if err != nil {
+			return nil, err
+		}
+		dbRecords = append(dbRecords, types.DBRecord{
+			ID:     id,
+			ZoneID: zone.ID,
+			Name:   record.Name,
+			Type:   odintypes.TypeToString(record.Type),
+			Class:  odintypes.ClassToString(record.Class),
+			TTL:    record.TTL,
+			RData:  rData,
+		})
+	}
+	return dbRecords, nil
+}
+
+// isZoneSOA reports whether record is the SOA at the apex of the named zone.
+func isZoneSOA(record *odintypes.DNSRecord, zoneName string) bool {
+	return record.Type == odintypes.TYPE_SOA && strings.EqualFold(record.Name, zoneName)
+}
@@ -0,0 +1,367 @@
+package server
+
+import (
+	"fmt"
+	"net"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Unfield/Odin-DNS/internal/parser"
+	"github.com/Unfield/Odin-DNS/internal/types"
+	"github.com/Unfield/Odin-DNS/pkg/odintypes"
+)
+
+// testPrimary serves a zone over UDP and TCP on the same port: SOA queries
+// over UDP, AXFR and IXFR over TCP, split into messages of two records each.
+type testPrimary struct {
+	t   *testing.T
+	udp net.PacketConn
+	tcp net.Listener
+
+	mu        sync.Mutex
+	soa       *odintypes.DNSRecord
+	axfr      []*odintypes.DNSRecord
+	ixfr      []*odintypes.DNSRecord
+	transfers []uint16
+}
+
+func startTestPrimary(t *testing.T) *testPrimary {
+	t.Helper()
+
+	primary := &testPrimary{t: t}
+	for attempt := 0; primary.udp == nil; attempt++ {
+		tcp, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		udp, err := net.ListenPacket("udp", tcp.Addr().String())
+		if err != nil {
+			tcp.Close()
+			if attempt == 10 {
+				t.Fatalf("no port free for both UDP and TCP: %v", err)
+			}
+			continue
+		}
+		primary.tcp, primary.udp = tcp, udp
+	}
+	t.Cleanup(primary.stop)
+
+	go primary.serveUDP()
+	go primary.serveTCP()
+	return primary
+}
+
+func (p *testPrimary) address() string {
+	return p.tcp.Addr().String()
+}
+
+func (p *testPrimary) stop() {
+	p.udp.Close()
+	p.tcp.Close()
+}
+
+// serve sets the zone content: the current SOA, the full zone for AXFR and the
+// answer to IXFR.
+func (p *testPrimary) serve(soa *odintypes.DNSRecord, axfr []*odintypes.DNSRecord, ixfr []*odintypes.DNSRecord) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.soa, p.axfr, p.ixfr = soa, axfr, ixfr
+}
+
+func (p *testPrimary) requestedTransfers() []uint16 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return slices.Clone(p.transfers)
+}
+
+func (p *testPrimary) serveUDP() {
+	buffer := make([]byte, parser.MaxTCPMessageSize)
+	for {
+		n, addr, err := p.udp.ReadFrom(buffer)
+		if err != nil {
+			return
+		}
+		request, err := parser.ParseRequest(buffer[:n])
+		if err != nil || len(request.Questions) != 1 || request.Questions[0].Type != odintypes.TYPE_SOA {
+			continue
+		}
+
+		p.mu.Lock()
+		response := p.response(&request, []*odintypes.DNSRecord{p.soa})
+		p.mu.Unlock()
+		message, err := parser.PackResponse(response)
+		if err != nil {
+			p.t.Errorf("primary failed to pack SOA answer: %v", err)
+			continue
+		}
+		p.udp.WriteTo(message, addr)
+	}
+}
+
+func (p *testPrimary) serveTCP() {
+	for {
+		conn, err := p.tcp.Accept()
+		if err != nil {
+			return
+		}
+		go p.transfer(conn)
+	}
+}
+
+func (p *testPrimary) transfer(conn net.Conn) {
+	defer conn.Close()
+
+	buffer, err := readMessage(conn, "tcp")
+	if err != nil {
+		return
+	}
+	request, err := parser.ParseRequest(buffer)
+	if err != nil || len(request.Questions) != 1 {
+		return
+	}
+
+	p.mu.Lock()
+	qtype := request.Questions[0].Type
+	p.transfers = append(p.transfers, qtype)
+	records := p.axfr
+	if qtype == odintypes.TYPE_IXFR {
+		records = p.ixfr
+	}
+	p.mu.Unlock()
+
+	for start := 0; start < len(records); start += 2 {
+		response := p.response(&request, records[start:min(start+2, len(records))])
+		message, err := parser.PackResponse(response)
+		if err != nil {
+			p.t.Errorf("primary failed to pack transfer message: %v", err)
+			return
+		}
+		if err := writeMessage(conn, "tcp", message); err != nil {
+			return
+		}
+	}
+}
+
+func (p *testPrimary) response(request *odintypes.DNSRequest, answers []*odintypes.DNSRecord) *odintypes.DNSRequest {
+	response := &odintypes.DNSRequest{Header: request.Header, Questions: request.Questions, Answers: answers}
+	response.Header.Flags.QR = true
+	response.Header.Flags.AA = true
+	return response
+}
+
+func testSOA(t *testing.T, zone string, serial uint32) *odintypes.DNSRecord {
+	t.Helper()
+	return testWireRecord(t, zone, "SOA", 3600, fmt.Sprintf("ns1.%s hostmaster.%s %d 3600 900 604800 300", zone, zone, serial))
+}
+
+func testWireRecord(t *testing.T, name string, rtype string, ttl uint32, rData string) *odintypes.DNSRecord {
+	t.Helper()
+	record, err := wireRecord(types.DBRecord{Name: name, Type: rtype, Class: "IN", TTL: ttl, RData: rData})
+	if err != nil {
+		t.Fatalf("invalid test record %s %s %s: %v", name, rtype, rData, err)
+	}
+	return record
+}
+
+// storedRecords lists the records of a zone as "name type rdata", sorted.
+func storedRecords(t *testing.T, store *memStore, zoneID string) []string {
+	t.Helper()
+	records, err := store.GetZoneEntries(zoneID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var entries []string
+	for _, record := range records {
+		entries = append(entries, record.Name+" "+record.Type+" "+record.RData)
+	}
+	slices.Sort(entries)
+	return entries
+}
+
+func TestSecondaryTransfers(t *testing.T) {
+	primary := startTestPrimary(t)
+
+	store := &memStore{}
+	store.addZone(types.DBZone{ID: "zone-secondary", Name: "example.org", Kind: types.ZoneKindSecondary, Primaries: primary.address()})
+	server, _ := newTestServer(t, store)
+	server.config.TRANSFER_TIMEOUT = 2 * time.Second
+
+	refresh := func() (time.Duration, error) {
+		zone, err := store.GetZone("zone-secondary")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return server.refreshSecondary(zone)
+	}
+	resolveWWW := func() []*odintypes.DNSRecord {
+		return resolveA(t, server, "www.example.org")
+	}
+
+	soa1, soa2, soa3 := testSOA(t, "example.org", 1), testSOA(t, "example.org", 2), testSOA(t, "example.org", 3)
+	ns := testWireRecord(t, "example.org", "NS", 3600, "ns1.example.org")
+	www1 := testWireRecord(t, "www.example.org", "A", 300, "192.0.2.1")
+	www2 := testWireRecord(t, "www.example.org", "A", 300, "192.0.2.2")
+	mail := testWireRecord(t, "mail.example.org", "A", 300, "192.0.2.3")
+
+	// an empty secondary pulls the whole zone
+	primary.serve(soa1, []*odintypes.DNSRecord{soa1, ns, www1, soa1}, nil)
+	next, err := refresh()
+	if err != nil {
+		t.Fatalf("initial refresh: %v", err)
+	}
+	if next != 3600*time.Second {
+		t.Errorf("next refresh in %v, want the SOA refresh of 1h", next)
+	}
+	want := []string{
+		"example.org NS ns1.example.org",
+		"example.org SOA ns1.example.org hostmaster.example.org 1 3600 900 604800 300",
+		"www.example.org A 192.0.2.1",
+	}
+	if got := storedRecords(t, store, "zone-secondary"); !slices.Equal(got, want) {
+		t.Fatalf("after AXFR the zone holds %q, want %q", got, want)
+	}
+	checkAddresses(t, resolveWWW(), "www.example.org", 300, "192.0.2.1")
+
+	zone, _ := store.GetZone("zone-secondary")
+	expiresIn := time.Until(zone.ExpiresAt.Time)
+	if !zone.ExpiresAt.Valid || expiresIn < 604800*time.Second-time.Minute || expiresIn > 604800*time.Second {
+		t.Errorf("zone expires in %v, want the SOA expire of 7 days", expiresIn)
+	}
+
+	// two changes in one IXFR: 1 -> 2 replaces www, 2 -> 3 adds mail
+	primary.serve(soa3, nil, []*odintypes.DNSRecord{
+		soa3,
+		soa1, www1, soa2, www2,
+		soa2, soa3, mail,
+		soa3,
+	})
+	if _, err := refresh(); err != nil {
+		t.Fatalf("incremental refresh: %v", err)
+	}
+	want = []string{
+		"example.org NS ns1.example.org",
+		"example.org SOA ns1.example.org hostmaster.example.org 3 3600 900 604800 300",
+		"mail.example.org A 192.0.2.3",
+		"www.example.org A 192.0.2.2",
+	}
+	if got := storedRecords(t, store, "zone-secondary"); !slices.Equal(got, want) {
+		t.Fatalf("after IXFR the zone holds %q, want %q", got, want)
+	}
+	checkAddresses(t, resolveWWW(), "www.example.org", 300, "192.0.2.2")
+
+	// an up to date secondary only compares serials
+	if _, err := refresh(); err != nil {
+		t.Fatalf("refresh without changes: %v", err)
+	}
+	if got := primary.requestedTransfers(); !slices.Equal(got, []uint16{odintypes.TYPE_AXFR, odintypes.TYPE_IXFR}) {
+		t.Errorf("primary saw transfers %v, want one AXFR and one IXFR", got)
+	}
+
+	// without a primary the zone is retried after the SOA retry interval and
+	// keeps its expiry
+	zone, _ = store.GetZone("zone-secondary")
+	primary.stop()
+	next, err = refresh()
+	if err == nil {
+		t.Fatal("refresh succeeded without a primary")
+	}
+	if next != 900*time.Second {
+		t.Errorf("retry in %v, want the SOA retry of 15m", next)
+	}
+	if unchanged, _ := store.GetZone("zone-secondary"); unchanged.ExpiresAt != zone.ExpiresAt {
+		t.Errorf("failed refresh moved the expiry from %v to %v", zone.ExpiresAt.Time, unchanged.ExpiresAt.Time)
+	}
+
+	// once expired the zone is no longer answered from
+	if err := server.cacheDriver.SetZoneExpiry("zone-secondary", time.Now().Add(-time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := server.resolve(odintypes.DNSQuestion{Name: "www.example.org", Type: odintypes.TYPE_A, Class: odintypes.CLASS_IN}, false); err == nil {
+		t.Error("expired zone was answered from")
+	}
+}
+
+func TestSecondaryRefreshTimers(t *testing.T) {
+	primary := startTestPrimary(t)
+	soa := testSOA(t, "example.org", 1)
+	primary.serve(soa, []*odintypes.DNSRecord{soa, soa}, nil)
+
+	store := &memStore{}
+	store.addZone(types.DBZone{ID: "zone-secondary", Name: "example.org", Kind: types.ZoneKindSecondary, Primaries: primary.address()})
+	server, _ := newTestServer(t, store)
+	server.config.TRANSFER_TIMEOUT = 2 * time.Second
+
+	refreshAt := func() time.Duration {
+		zone, err := store.GetZone("zone-secondary")
+		if err != nil {
+			t.Fatal(err)
+		}
+		server.secondaryZones[zone.ID] = &secondaryState{running: true}
+		server.refreshZone(*zone)
+
+		state := server.secondaryZones[zone.ID]
+		if state.running {
+			t.Error("zone still marked as refreshing")
+		}
+		return time.Until(state.nextRefresh).Round(time.Minute)
+	}
+
+	if next := refreshAt(); next != time.Hour {
+		t.Errorf("after a transfer the next refresh is in %v, want the SOA refresh of 1h", next)
+	}
+
+	primary.stop()
+	if next := refreshAt(); next != 15*time.Minute {
+		t.Errorf("after a failure the next refresh is in %v, want the SOA retry of 15m", next)
+	}
+
+	// a NOTIFY during the refresh schedules another one right away
+	server.secondaryZones["zone-secondary"] = &secondaryState{running: true}
+	server.requestRefresh("zone-secondary")
+	zone, _ := store.GetZone("zone-secondary")
+	server.refreshZone(*zone)
+	if state := server.secondaryZones["zone-secondary"]; !state.nextRefresh.IsZero() {
+		t.Errorf("refresh requested during a refresh is due at %v, want now", state.nextRefresh)
+	}
+}
+
+func TestTransferComplete(t *testing.T) {
+	soa := func(serial uint32) *odintypes.DNSRecord {
+		return testSOA(t, "example.org", serial)
+	}
+	a := testWireRecord(t, "www.example.org", "A", 300, "192.0.2.1")
+	local := &odintypes.SOAData{Serial: 3}
+
+	tests := []struct {
+		name     string
+		localSOA *odintypes.SOAData
+		records  []*odintypes.DNSRecord
+		want     bool
+		wantErr  bool
+	}{
+		{name: "nothing yet", records: nil},
+		{name: "no leading SOA", records: []*odintypes.DNSRecord{a}, wantErr: true},
+		{name: "up to date", localSOA: local, records: []*odintypes.DNSRecord{soa(3)}, want: true},
+		{name: "first SOA of a transfer", localSOA: local, records: []*odintypes.DNSRecord{soa(4)}},
+		{name: "first SOA of an AXFR", records: []*odintypes.DNSRecord{soa(4)}},
+		{name: "AXFR in progress", records: []*odintypes.DNSRecord{soa(4), a}},
+		{name: "AXFR complete", records: []*odintypes.DNSRecord{soa(4), a, soa(4)}, want: true},
+		{name: "IXFR in progress", localSOA: local, records: []*odintypes.DNSRecord{soa(5), soa(3), a, soa(4)}},
+		{name: "IXFR ends with an old SOA", localSOA: local, records: []*odintypes.DNSRecord{soa(5), soa(3), soa(4), soa(4)}},
+		{name: "IXFR missing the closing SOA", localSOA: local, records: []*odintypes.DNSRecord{soa(5), soa(3), soa(4), soa(4), soa(5)}},
+		{name: "IXFR complete", localSOA: local, records: []*odintypes.DNSRecord{soa(5), soa(3), a, soa(4), soa(4), soa(5), a, soa(5)}, want: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := transferComplete("example.org", test.localSOA)(test.records)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("error = %v, want error %v", err, test.wantErr)
+			}
+			if got != test.want {
+				t.Errorf("complete = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log/slog"
 	"net"
+	"sync"
 	"time"
 
 	"github.com/Unfield/Odin-DNS/internal/config"
@@ -15,6 +16,34 @@ import (
 	"github.com/Unfield/Odin-DNS/pkg/odintypes"
 )
 
+type Server struct {
+	config          *config.Config
+	logger          *slog.Logger
+	ingestionDriver metrics.MetricsIngestionDriver
+	cacheDriver     *redis.RedisCacheDriver
+	aliasResolver   AliasResolver
+	anyClients      []*net.IPNet
+	updateClients   []*net.IPNet
+
+	notifyMu       sync.Mutex
+	notifyInFlight map[string]chan struct{}
+
+	secondaryMu    sync.Mutex
+	secondaryZones map[string]*secondaryState
+	refreshNow     chan struct{}
+
+	// updateMu serializes dynamic updates, their prerequisites are checked
+	// against the zone before the change is written
+	updateMu sync.Mutex
+}
+
+// responseWriter hides the transport a query arrived on from handleRequest.
+type responseWriter interface {
+	RemoteAddr() net.Addr
+	Network() string
+	WriteResponse(response *odintypes.DNSRequest, maxSize int) error
+}
+
 func StartServer(config *config.Config) {
 	logger := slog.Default().WithGroup("DNS-Server")
 
@@ -38,55 +67,83 @@ func StartServer(config *config.Config) {
 
 	cacheDriver := redis.NewRedisCacheDriver(mysqlDriver, config.REDIS_HOST, config.REDIS_USERNAME, config.REDIS_PASSWORD, config.REDIS_DATABASE)
 
-	addr, err := net.ResolveUDPAddr("udp", fmt.Sprintf("%s:%d", config.DNS_HOST, config.DNS_PORT))
+	server := &Server{
+		config:          config,
+		logger:          logger,
+		ingestionDriver: ingestionDriver,
+		cacheDriver:     cacheDriver,
+		notifyInFlight:  make(map[string]chan struct{}),
+		secondaryZones:  make(map[string]*secondaryState),
+		refreshNow:      make(chan struct{}, 1),
+	}
+	server.anyClients, err = util.ParseClientNets(config.ANY_ALLOWED_CLIENTS)
 	if err != nil {
-		logger.Error("Error resolving address", "port", config.DNS_PORT, "error", err)
+		logger.Error("Invalid ANY_ALLOWED_CLIENTS", "error", err)
 		return
 	}
-
-	conn, err := net.ListenUDP("udp", addr)
+	server.updateClients, err = util.ParseClientNets(config.UPDATE_ALLOWED_CLIENTS)
 	if err != nil {
-		logger.Error("Error listening on UDP port", "port", config.DNS_PORT, "error", err)
+		logger.Error("Invalid UPDATE_ALLOWED_CLIENTS", "error", err)
 		return
 	}
-	defer conn.Close()
-
-	logger.Info("Odin DNS server is running", "port", addr.Port)
+	if config.ALIAS_UPSTREAM != "" {
+		server.aliasResolver = NewUpstreamAliasResolver(config.ALIAS_UPSTREAM, config.ALIAS_UPSTREAM_TIMEOUT)
+	}
 
-	buffer := make([]byte, config.BUFFER_SIZE)
+	go server.pruneJournal()
+	go server.watchZoneChanges()
+	go server.runSecondaries()
 
-	for {
-		n, clientAddr, err := conn.ReadFromUDP(buffer)
+	if config.DNS_TCP_ENABLED {
+		tcpListener, err := server.listenTCP()
 		if err != nil {
-			logger.Error("Error reading from UDP", "error", err)
-			continue
+			logger.Error("Error listening on TCP port", "port", config.DNS_PORT, "error", err)
+			return
 		}
+		defer tcpListener.Close()
 
-		requestDataCopy := make([]byte, n)
-		copy(requestDataCopy, buffer[:n])
+		go server.serveTCP(tcpListener)
+	}
 
-		go handleRequest(clientAddr, conn, requestDataCopy, logger, ingestionDriver, cacheDriver)
+	if err := server.serveUDP(); err != nil {
+		logger.Error("UDP server stopped", "port", config.DNS_PORT, "error", err)
 	}
 }
 
-func SendResponse(response *odintypes.DNSRequest, conn *net.UDPConn, clientAddr *net.UDPAddr) error {
-	binaryResponse, err := parser.PackResponse(response)
-	if err != nil {
-		return fmt.Errorf("Error packing DNS response: %w", err)
+// maxResponseSize returns the largest response the client accepts on the transport
+// the query arrived on. Over UDP this is 512 bytes unless the client advertised a
+// larger payload size via EDNS, capped by our own EDNS_UDP_PAYLOAD_SIZE.
+func (s *Server) maxResponseSize(w responseWriter, request *odintypes.DNSRequest) int {
+	if w.Network() == "tcp" {
+		return parser.MaxTCPMessageSize
 	}
-	_, err = conn.WriteToUDP(binaryResponse, clientAddr)
-	if err != nil {
-		return fmt.Errorf("Error writing DNS response to UDP: %w", err)
+	if request == nil || request.EDNS == nil {
+		return parser.MaxUDPMessageSize
+	}
+	size := min(int(request.EDNS.UDPPayloadSize), s.config.EDNS_UDP_PAYLOAD_SIZE)
+	return max(size, parser.MaxUDPMessageSize)
+}
+
+func clientIP(addr net.Addr) net.IP {
+	switch a := addr.(type) {
+	case *net.UDPAddr:
+		return a.IP
+	case *net.TCPAddr:
+		return a.IP
+	default:
+		return nil
 	}
-	return nil
 }
 
-func handleRequest(clientAddr *net.UDPAddr, conn *net.UDPConn, buffer []byte, logger *slog.Logger, ingestionDriver metrics.MetricsIngestionDriver, cacheDriver *redis.RedisCacheDriver) {
+func (s *Server) handleRequest(w responseWriter, buffer []byte) {
 	startTime := time.Now()
+	clientAddr := w.RemoteAddr()
+	logger := s.logger
+	maxSize := s.maxResponseSize(w, nil)
 
 	currentMetric := metrics.DNSMetric{
 		Timestamp: time.Now(),
-		IP:        clientAddr.IP.String(),
+		IP:        clientIP(clientAddr).String(),
 		Success:   1,
 		Domain:    "N/A",
 		QueryType: "N/A",
@@ -117,21 +174,51 @@ func handleRequest(clientAddr *net.UDPAddr, conn *net.UDPConn, buffer []byte, lo
 	if parseErr != nil {
 		logger.Error("Error parsing DNS request", "error", parseErr, "client", clientAddr.String())
 		response.Header.Flags.RCode = 1
+		if len(buffer) >= 2 {
+			response.Header.ID = uint16(buffer[0])<<8 | uint16(buffer[1])
+		}
 
 		currentMetric.Success = 0
 		currentMetric.ErrorMessage = fmt.Sprintf("FORMERR: %v", parseErr)
 		currentMetric.Rcode = response.Header.Flags.RCode
 
-		if sendErr := SendResponse(response, conn, clientAddr); sendErr != nil {
+		if sendErr := w.WriteResponse(response, maxSize); sendErr != nil {
 			logger.Error("Error sending FORMERR response", "error", sendErr)
 		}
 		currentMetric.ResponseTimeMs = float64(time.Since(startTime).Milliseconds())
-		ingestionDriver.Collect(currentMetric)
+		s.ingestionDriver.Collect(currentMetric)
+		return
 	}
 
 	response.Header.ID = req.Header.ID
+	response.Header.Flags.Opcode = req.Header.Flags.Opcode
 	response.Header.QDCount = req.Header.QDCount
 	response.Questions = req.Questions
+	maxSize = s.maxResponseSize(w, &req)
+
+	if req.EDNS != nil {
+		response.EDNS = &odintypes.EDNS{
+			UDPPayloadSize: uint16(s.config.EDNS_UDP_PAYLOAD_SIZE),
+			Version:        odintypes.EDNS_VERSION,
+			DO:             req.EDNS.DO,
+		}
+
+		if req.EDNS.Version > odintypes.EDNS_VERSION {
+			logger.Warn("Unsupported EDNS version", "version", req.EDNS.Version, "client", clientAddr.String(), "id", req.Header.ID)
+			response.Header.Flags.RCode = odintypes.RCODE_BADVERS
+
+			currentMetric.Success = 0
+			currentMetric.ErrorMessage = fmt.Sprintf("BADVERS: EDNS version %d", req.EDNS.Version)
+			currentMetric.Rcode = response.Header.Flags.RCode
+
+			if sendErr := w.WriteResponse(response, maxSize); sendErr != nil {
+				logger.Error("Error sending BADVERS response", "error", sendErr)
+			}
+			currentMetric.ResponseTimeMs = float64(time.Since(startTime).Milliseconds())
+			s.ingestionDriver.Collect(currentMetric)
+			return
+		}
+	}
 
 	logger.Debug("Received DNS request", "client", clientAddr.String(), "request", req)
 
@@ -151,23 +238,84 @@ func handleRequest(clientAddr *net.UDPAddr, conn *net.UDPConn, buffer []byte, lo
 		currentMetric.ErrorMessage = "FORMERR: No questions in request"
 		currentMetric.Rcode = response.Header.Flags.RCode
 
-		if sendErr := SendResponse(response, conn, clientAddr); sendErr != nil {
+		if sendErr := w.WriteResponse(response, maxSize); sendErr != nil {
 			logger.Error("Error sending NoQuestions response", "error", sendErr)
 		}
 		currentMetric.ResponseTimeMs = float64(time.Since(startTime).Milliseconds())
-		ingestionDriver.Collect(currentMetric)
+		s.ingestionDriver.Collect(currentMetric)
 		return
 	}
 
 	logger.Info("Processing DNS request", "domain", currentMetric.Domain, "type", currentMetric.QueryType)
 
-	var cacheHit uint8
-
 	question := req.Questions[0]
 
-	dnsRecord, cacheHit, err := cacheDriver.LookupRecordForDNSQuery(question.Name, question.Type, question.Class)
-	currentMetric.CacheHit = cacheHit
+	switch req.Header.Flags.Opcode {
+	case odintypes.OPCODE_QUERY:
+	case odintypes.OPCODE_NOTIFY:
+		rcode, err := s.handleNotify(w, response, question, maxSize)
+		currentMetric.Rcode = rcode
+		if err != nil {
+			logger.Error("Failed to handle NOTIFY", "zone", question.Name, "client", clientAddr.String(), "error", err)
+			currentMetric.Success = 0
+			currentMetric.ErrorMessage = fmt.Sprintf("NOTIFY failed: %v", err)
+		} else if rcode != odintypes.RCODE_NOERROR {
+			currentMetric.Success = 0
+			currentMetric.ErrorMessage = fmt.Sprintf("NOTIFY refused with RCODE %d", rcode)
+		}
+		currentMetric.ResponseTimeMs = float64(time.Since(startTime).Milliseconds())
+		s.ingestionDriver.Collect(currentMetric)
+		return
+	case odintypes.OPCODE_UPDATE:
+		rcode, err := s.handleUpdate(w, &req, response, maxSize)
+		currentMetric.Rcode = rcode
+		if err != nil {
+			logger.Error("Failed to handle UPDATE", "zone", question.Name, "client", clientAddr.String(), "error", err)
+			currentMetric.Success = 0
+			currentMetric.ErrorMessage = fmt.Sprintf("UPDATE failed: %v", err)
+		} else if rcode != odintypes.RCODE_NOERROR {
+			currentMetric.Success = 0
+			currentMetric.ErrorMessage = fmt.Sprintf("UPDATE rejected with RCODE %d", rcode)
+		}
+		currentMetric.ResponseTimeMs = float64(time.Since(startTime).Milliseconds())
+		s.ingestionDriver.Collect(currentMetric)
+		return
+	default:
+		logger.Warn("Unsupported opcode", "opcode", req.Header.Flags.Opcode, "client", clientAddr.String(), "id", req.Header.ID)
+		response.Header.Flags.RCode = odintypes.RCODE_NOTIMP
+
+		currentMetric.Success = 0
+		currentMetric.ErrorMessage = fmt.Sprintf("NOTIMP: opcode %d", req.Header.Flags.Opcode)
+		currentMetric.Rcode = response.Header.Flags.RCode
+
+		if sendErr := w.WriteResponse(response, maxSize); sendErr != nil {
+			logger.Error("Error sending NOTIMP response", "error", sendErr)
+		}
+		currentMetric.ResponseTimeMs = float64(time.Since(startTime).Milliseconds())
+		s.ingestionDriver.Collect(currentMetric)
+		return
+	}
+
+	if question.Type == odintypes.TYPE_AXFR || question.Type == odintypes.TYPE_IXFR {
+		rcode, err := s.transferZone(w, &req, response, question)
+		currentMetric.Rcode = rcode
+		if err != nil {
+			logger.Error("Zone transfer failed", "zone", question.Name, "client", clientAddr.String(), "error", err)
+			currentMetric.Success = 0
+			currentMetric.ErrorMessage = fmt.Sprintf("%s failed: %v", currentMetric.QueryType, err)
+		} else if rcode != odintypes.RCODE_NOERROR {
+			currentMetric.Success = 0
+			currentMetric.ErrorMessage = fmt.Sprintf("%s refused with RCODE %d", currentMetric.QueryType, rcode)
+		}
+		currentMetric.ResponseTimeMs = float64(time.Since(startTime).Milliseconds())
+		s.ingestionDriver.Collect(currentMetric)
+		return
+	}
+
+	// RFC 8482: only TCP and trusted clients get the full answer to ANY queries
+	fullANY := w.Network() == "tcp" && s.config.ANY_FULL_OVER_TCP || util.ClientAllowed(s.anyClients, clientIP(clientAddr))
 
+	result, err := s.resolve(question, fullANY)
 	if err != nil {
 		logger.Error("Database lookup error", "name", question.Name, "type", question.Type, "class", question.Class, "error", err)
 		response.Header.Flags.RCode = 2
@@ -182,45 +330,60 @@ func handleRequest(clientAddr *net.UDPAddr, conn *net.UDPConn, buffer []byte, lo
 		response.Header.ANCount = 0
 		response.Header.NSCount = 0
 		response.Header.ARCount = 0
-		if sendErr := SendResponse(response, conn, clientAddr); sendErr != nil {
+		if sendErr := w.WriteResponse(response, maxSize); sendErr != nil {
 			logger.Error("Error sending SERVFAIL response", "error", sendErr)
 		}
 		currentMetric.ResponseTimeMs = float64(time.Since(startTime).Milliseconds())
-		ingestionDriver.Collect(currentMetric)
+		s.ingestionDriver.Collect(currentMetric)
 		return
 	}
+	currentMetric.CacheHit = result.cacheHit
+
+	response.Header.Flags.RCode = result.rcode
+	response.Header.Flags.AA = result.authoritative
+	response.Answers = append(response.Answers, result.answers...)
+	response.Authority = append(response.Authority, result.authority...)
+	response.Additional = append(response.Additional, result.additional...)
 
-	if dnsRecord == nil {
-		logger.Warn("Resource Record not found (from DB)", "name", question.Name, "type", question.Type, "class", question.Class, "client", clientAddr.String(), "id", req.Header.ID)
-		response.Header.Flags.RCode = 3
+	if result.rcode == odintypes.RCODE_NXDOMAIN {
+		logger.Warn("Domain name not found", "name", question.Name, "type", question.Type, "class", question.Class, "client", clientAddr.String(), "id", req.Header.ID)
 
 		currentMetric.Success = 0
-		currentMetric.ErrorMessage = "NXDOMAIN: Record not found"
+		currentMetric.ErrorMessage = "NXDOMAIN: Domain name not found"
 		currentMetric.Rcode = response.Header.Flags.RCode
 
-		response.Answers = []*odintypes.DNSRecord{}
-		response.Authority = []*odintypes.DNSRecord{}
-		response.Additional = []*odintypes.DNSRecord{}
-		response.Header.ANCount = 0
-		response.Header.NSCount = 0
-		response.Header.ARCount = 0
-		if sendErr := SendResponse(response, conn, clientAddr); sendErr != nil {
+		if sendErr := w.WriteResponse(response, maxSize); sendErr != nil {
 			logger.Error("Error sending NXDOMAIN response", "error", sendErr)
 		}
 		currentMetric.ResponseTimeMs = float64(time.Since(startTime).Milliseconds())
-		ingestionDriver.Collect(currentMetric)
+		s.ingestionDriver.Collect(currentMetric)
+		return
+	}
+
+	if result.rcode == odintypes.RCODE_REFUSED {
+		logger.Info("Refusing query for name outside our zones", "name", question.Name, "type", question.Type, "class", question.Class, "client", clientAddr.String(), "id", req.Header.ID)
+
+		currentMetric.Success = 0
+		currentMetric.ErrorMessage = "REFUSED: Not authoritative for zone"
+		currentMetric.Rcode = response.Header.Flags.RCode
+
+		if sendErr := w.WriteResponse(response, maxSize); sendErr != nil {
+			logger.Error("Error sending REFUSED response", "error", sendErr)
+		}
+		currentMetric.ResponseTimeMs = float64(time.Since(startTime).Milliseconds())
+		s.ingestionDriver.Collect(currentMetric)
 		return
 	}
 
-	response.Answers = append(response.Answers, dnsRecord)
-	response.Header.ANCount = response.Header.ANCount + 1
-	response.Header.Flags.AA = true
+	if len(response.Answers) == 0 {
+		logger.Info("No data for existing name", "name", question.Name, "type", question.Type, "class", question.Class, "client", clientAddr.String(), "id", req.Header.ID)
+	}
 
 	currentMetric.Success = 1
 	currentMetric.ErrorMessage = ""
 	currentMetric.Rcode = response.Header.Flags.RCode
 
-	if sendErr := SendResponse(response, conn, clientAddr); sendErr != nil {
+	if sendErr := w.WriteResponse(response, maxSize); sendErr != nil {
 		logger.Error("Error sending DNS response", "error", sendErr, "client", clientAddr.String(), "domain", currentMetric.Domain)
 		currentMetric.Success = 0
 		currentMetric.ErrorMessage = fmt.Sprintf("SendResponse failed: %v", sendErr)
@@ -228,5 +391,5 @@ func handleRequest(clientAddr *net.UDPAddr, conn *net.UDPConn, buffer []byte, lo
 	}
 
 	currentMetric.ResponseTimeMs = float64(time.Since(startTime).Milliseconds())
-	ingestionDriver.Collect(currentMetric)
+	s.ingestionDriver.Collect(currentMetric)
 }
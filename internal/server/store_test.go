@@ -0,0 +1,243 @@
+package server
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Unfield/Odin-DNS/internal/config"
+	"github.com/Unfield/Odin-DNS/internal/datastore"
+	redis "github.com/Unfield/Odin-DNS/internal/datastore/Redis"
+	"github.com/Unfield/Odin-DNS/internal/types"
+	"github.com/Unfield/Odin-DNS/pkg/odintypes"
+	"github.com/alicebob/miniredis/v2"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// memStore stands in for the MySQL driver. It implements what the server and the
+// Redis cache reach in these tests, anything else hits the nil Driver and panics.
+type memStore struct {
+	datastore.Driver
+
+	mu      sync.Mutex
+	zones   []types.DBZone
+	records []types.DBRecord
+	// lookups counts the RRset lookups that made it past the cache
+	lookups int
+	// changes counts the calls to ApplyZoneChange
+	changes int
+}
+
+func (m *memStore) addZone(zone types.DBZone) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.zones = append(m.zones, zone)
+}
+
+func (m *memStore) addRecord(zoneID string, name string, rtype string, ttl uint32, rData string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.records = append(m.records, types.DBRecord{
+		ID:     fmt.Sprintf("record-%d", len(m.records)),
+		ZoneID: zoneID,
+		Name:   name,
+		Type:   rtype,
+		Class:  "IN",
+		TTL:    ttl,
+		RData:  rData,
+	})
+}
+
+func (m *memStore) zone(id string) *types.DBZone {
+	for i := range m.zones {
+		if m.zones[i].ID == id {
+			return &m.zones[i]
+		}
+	}
+	return nil
+}
+
+func (m *memStore) GetZone(id string) (*types.DBZone, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if zone := m.zone(id); zone != nil {
+		copied := *zone
+		return &copied, nil
+	}
+	return nil, nil
+}
+
+func (m *memStore) GetAllZones() ([]types.DBZone, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return slices.Clone(m.zones), nil
+}
+
+func (m *memStore) GetSecondaryZones() ([]types.DBZone, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var zones []types.DBZone
+	for _, zone := range m.zones {
+		if zone.IsSecondary() {
+			zones = append(zones, zone)
+		}
+	}
+	return zones, nil
+}
+
+func (m *memStore) SetZoneExpiry(zoneId string, expiresAt time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	zone := m.zone(zoneId)
+	if zone == nil {
+		return fmt.Errorf("zone %s not found", zoneId)
+	}
+	zone.ExpiresAt.Time, zone.ExpiresAt.Valid = expiresAt, true
+	return nil
+}
+
+func (m *memStore) GetZoneEntries(zoneId string) ([]types.DBRecord, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var records []types.DBRecord
+	for _, record := range m.records {
+		if record.ZoneID == zoneId {
+			records = append(records, record)
+		}
+	}
+	return records, nil
+}
+
+func (m *memStore) GetZoneSOA(zoneId string) (*types.DBRecord, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	zone := m.zone(zoneId)
+	for _, record := range m.records {
+		if record.ZoneID == zoneId && record.Type == "SOA" && strings.EqualFold(record.Name, zone.Name) {
+			return &record, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *memStore) LookupRecordForDNSQuery(zoneID string, rname string, rtype uint16, rclass uint16) ([]*odintypes.DNSRecord, uint8, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lookups++
+
+	var rrset []*odintypes.DNSRecord
+	for _, record := range m.records {
+		if record.ZoneID != zoneID || !strings.EqualFold(record.Name, rname) || record.Type != odintypes.TypeToString(rtype) || record.Class != odintypes.ClassToString(rclass) {
+			continue
+		}
+		wire, err := wireRecord(record)
+		if err != nil {
+			return nil, 0, err
+		}
+		rrset = append(rrset, wire)
+	}
+	return rrset, 0, nil
+}
+
+func (m *memStore) LookupAddressRecords(zoneID string, rnames []string, rclass uint16) ([]*odintypes.DNSRecord, uint8, error) {
+	var records []*odintypes.DNSRecord
+	for _, rname := range rnames {
+		for _, rtype := range []uint16{odintypes.TYPE_A, odintypes.TYPE_AAAA} {
+			rrset, _, err := m.LookupRecordForDNSQuery(zoneID, rname, rtype, rclass)
+			if err != nil {
+				return nil, 0, err
+			}
+			records = append(records, rrset...)
+		}
+	}
+	return records, 0, nil
+}
+
+func (m *memStore) LookupOwnerNames(zoneID string, rclass uint16) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var names []string
+	for _, record := range m.records {
+		if record.ZoneID == zoneID && record.Class == odintypes.ClassToString(rclass) && !slices.Contains(names, record.Name) {
+			names = append(names, record.Name)
+		}
+	}
+	return names, nil
+}
+
+func (m *memStore) ReplaceZoneRecords(zoneId string, records []types.DBRecord) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.records = slices.DeleteFunc(m.records, func(record types.DBRecord) bool {
+		return record.ZoneID == zoneId
+	})
+	m.records = append(m.records, records...)
+	return nil
+}
+
+// ApplyZoneDelta matches deleted records by name, type, class and RDATA like the
+// MySQL driver, which compares RDATA by hash.
+func (m *memStore) ApplyZoneDelta(zoneId string, deleted []types.DBRecord, added []types.DBRecord) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, gone := range deleted {
+		m.records = slices.DeleteFunc(m.records, func(record types.DBRecord) bool {
+			return record.ZoneID == zoneId && strings.EqualFold(record.Name, gone.Name) &&
+				record.Type == gone.Type && record.Class == gone.Class && record.RData == gone.RData
+		})
+	}
+	m.records = append(m.records, added...)
+	return nil
+}
+
+// ApplyZoneChange deletes records by ID and bumps the serial of the zone's SOA
+// like the MySQL driver, without keeping a journal.
+func (m *memStore) ApplyZoneChange(zoneId string, deleted []types.DBRecord, added []types.DBRecord) (*types.DBRecord, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.changes++
+
+	for _, gone := range deleted {
+		m.records = slices.DeleteFunc(m.records, func(record types.DBRecord) bool {
+			return record.ID == gone.ID
+		})
+	}
+	m.records = append(m.records, added...)
+
+	for i := range m.records {
+		if m.records[i].ZoneID != zoneId || m.records[i].Type != "SOA" {
+			continue
+		}
+		soa, err := odintypes.ParseSOAData(m.records[i].RData)
+		if err != nil {
+			return nil, err
+		}
+		soa.Serial = odintypes.NextSOASerial(soa.Serial, time.Now().UTC())
+		m.records[i].RData = soa.String()
+		copied := m.records[i]
+		return &copied, nil
+	}
+	return nil, fmt.Errorf("zone %s has no SOA record", zoneId)
+}
+
+// newTestServer returns a server on top of store, cached in a fresh miniredis.
+func newTestServer(t *testing.T, store *memStore) (*Server, *miniredis.Miniredis) {
+	t.Helper()
+
+	cache := miniredis.RunT(t)
+	client := goredis.NewClient(&goredis.Options{Addr: cache.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	return &Server{
+		config:         config.DefaultConfig(),
+		logger:         slog.Default(),
+		cacheDriver:    redis.NewRedisCacheDriverWithClient(store, client),
+		notifyInFlight: make(map[string]chan struct{}),
+		secondaryZones: make(map[string]*secondaryState),
+		refreshNow:     make(chan struct{}, 1),
+	}, cache
+}
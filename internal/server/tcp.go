@@ -0,0 +1,159 @@
+package server
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/Unfield/Odin-DNS/internal/parser"
+	"github.com/Unfield/Odin-DNS/pkg/odintypes"
+)
+
+// tcpResponseWriter frames responses with the two byte length prefix from RFC 1035 4.2.2.
+// Pipelined queries are answered concurrently, so writes are serialized per connection.
+type tcpResponseWriter struct {
+	conn         *net.TCPConn
+	writeTimeout time.Duration
+	mu           sync.Mutex
+}
+
+func (w *tcpResponseWriter) RemoteAddr() net.Addr {
+	return w.conn.RemoteAddr()
+}
+
+func (w *tcpResponseWriter) Network() string {
+	return "tcp"
+}
+
+func (w *tcpResponseWriter) WriteResponse(response *odintypes.DNSRequest, maxSize int) error {
+	binaryResponse, err := parser.PackResponseWithLimit(response, maxSize)
+	if err != nil {
+		return fmt.Errorf("Error packing DNS response: %w", err)
+	}
+
+	message := make([]byte, 2+len(binaryResponse))
+	binary.BigEndian.PutUint16(message, uint16(len(binaryResponse)))
+	copy(message[2:], binaryResponse)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if err := w.conn.SetWriteDeadline(time.Now().Add(w.writeTimeout)); err != nil {
+		return fmt.Errorf("Error setting TCP write deadline: %w", err)
+	}
+	if _, err := w.conn.Write(message); err != nil {
+		return fmt.Errorf("Error writing DNS response to TCP: %w", err)
+	}
+	return nil
+}
+
+func (s *Server) listenTCP() (*net.TCPListener, error) {
+	addr, err := net.ResolveTCPAddr("tcp", fmt.Sprintf("%s:%d", s.config.DNS_HOST, s.config.DNS_PORT))
+	if err != nil {
+		return nil, fmt.Errorf("error resolving address: %w", err)
+	}
+
+	listener, err := net.ListenTCP("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Odin DNS server is running", "protocol", "tcp", "port", addr.Port)
+	return listener, nil
+}
+
+// serveTCP accepts connections up to TCP_MAX_CONNECTIONS at a time, a limit of 0
+// accepts any number.
+func (s *Server) serveTCP(listener *net.TCPListener) {
+	var connectionSlots chan struct{}
+	if s.config.TCP_MAX_CONNECTIONS > 0 {
+		connectionSlots = make(chan struct{}, s.config.TCP_MAX_CONNECTIONS)
+	}
+
+	for {
+		conn, err := listener.AcceptTCP()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			s.logger.Error("Error accepting TCP connection", "error", err)
+			continue
+		}
+
+		if connectionSlots == nil {
+			go s.handleTCPConnection(conn)
+			continue
+		}
+
+		select {
+		case connectionSlots <- struct{}{}:
+		default:
+			s.logger.Warn("TCP connection limit reached; closing connection", "client", conn.RemoteAddr().String(), "limit", s.config.TCP_MAX_CONNECTIONS)
+			conn.Close()
+			continue
+		}
+
+		go func() {
+			defer func() { <-connectionSlots }()
+			s.handleTCPConnection(conn)
+		}()
+	}
+}
+
+// handleTCPConnection reads length prefixed queries until the client closes the
+// connection or stays idle for longer than TCP_IDLE_TIMEOUT (RFC 7766 6.2.3).
+// Up to TCP_MAX_PIPELINED queries are answered at once, at least one.
+func (s *Server) handleTCPConnection(conn *net.TCPConn) {
+	defer conn.Close()
+
+	writer := &tcpResponseWriter{conn: conn, writeTimeout: s.config.TCP_IDLE_TIMEOUT}
+	inFlight := make(chan struct{}, max(s.config.TCP_MAX_PIPELINED, 1))
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	lengthPrefix := make([]byte, 2)
+
+	for {
+		if err := conn.SetReadDeadline(time.Now().Add(s.config.TCP_IDLE_TIMEOUT)); err != nil {
+			s.logger.Error("Error setting TCP read deadline", "error", err, "client", conn.RemoteAddr().String())
+			return
+		}
+
+		if _, err := io.ReadFull(conn, lengthPrefix); err != nil {
+			if !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, net.ErrClosed) && !isTimeout(err) {
+				s.logger.Error("Error reading TCP message length", "error", err, "client", conn.RemoteAddr().String())
+			}
+			return
+		}
+
+		messageLength := binary.BigEndian.Uint16(lengthPrefix)
+		if messageLength == 0 {
+			s.logger.Warn("Received empty TCP message; closing connection", "client", conn.RemoteAddr().String())
+			return
+		}
+
+		message := make([]byte, messageLength)
+		if _, err := io.ReadFull(conn, message); err != nil {
+			s.logger.Error("Error reading TCP message", "error", err, "client", conn.RemoteAddr().String())
+			return
+		}
+
+		inFlight <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-inFlight }()
+			s.handleRequest(writer, message)
+		}()
+	}
+}
+
+func isTimeout(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
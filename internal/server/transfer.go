@@ -0,0 +1,284 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Unfield/Odin-DNS/internal/parser"
+	"github.com/Unfield/Odin-DNS/internal/types"
+	"github.com/Unfield/Odin-DNS/internal/util"
+	"github.com/Unfield/Odin-DNS/pkg/odintypes"
+)
+
+// transferMessageSize is the estimated size transfer messages are filled up to.
+// It stays well below the 64 KiB TCP limit so a bad estimate can not overflow it.
+const transferMessageSize = 16 * 1024
+
+// transferZone answers AXFR (RFC 5936) and IXFR (RFC 1995) queries, but only for
+// clients on the zone's transfer ACL. An AXFR streams the zone over TCP as a sequence
+// of messages that starts and ends with the apex SOA. It writes all responses itself
+// and returns the RCODE for the metrics.
+func (s *Server) transferZone(w responseWriter, request *odintypes.DNSRequest, response *odintypes.DNSRequest, question odintypes.DNSQuestion) (uint8, error) {
+	if w.Network() != "tcp" && question.Type == odintypes.TYPE_AXFR {
+		// AXFR over UDP is not defined (RFC 5936 4.2)
+		return s.refuseTransfer(w, response, odintypes.RCODE_NOTIMP)
+	}
+
+	zone, err := s.cacheDriver.FindZoneForName(question.Name)
+	if err != nil {
+		s.refuseTransfer(w, response, odintypes.RCODE_SERVFAIL)
+		return odintypes.RCODE_SERVFAIL, err
+	}
+	if zone == nil || !strings.EqualFold(zone.Name, strings.TrimSuffix(question.Name, ".")) {
+		return s.refuseTransfer(w, response, odintypes.RCODE_NOTAUTH)
+	}
+	if zone.Expired(time.Now()) {
+		s.refuseTransfer(w, response, odintypes.RCODE_SERVFAIL)
+		return odintypes.RCODE_SERVFAIL, fmt.Errorf("secondary zone %s has expired", zone.Name)
+	}
+
+	allowed, err := s.transferAllowed(zone, w)
+	if err != nil {
+		s.refuseTransfer(w, response, odintypes.RCODE_SERVFAIL)
+		return odintypes.RCODE_SERVFAIL, err
+	}
+	if !allowed {
+		s.logger.Warn("Zone transfer refused", "zone", zone.Name, "client", w.RemoteAddr().String())
+		return s.refuseTransfer(w, response, odintypes.RCODE_REFUSED)
+	}
+
+	var records []*odintypes.DNSRecord
+	if question.Type == odintypes.TYPE_IXFR {
+		records, err = s.incrementalRecords(w, request, zone)
+		if err == errMissingClientSerial {
+			return s.refuseTransfer(w, response, odintypes.RCODE_FORMERR)
+		}
+	} else {
+		records, err = s.fullZoneRecords(zone)
+	}
+	if err != nil {
+		s.refuseTransfer(w, response, odintypes.RCODE_SERVFAIL)
+		return odintypes.RCODE_SERVFAIL, err
+	}
+
+	if err := s.streamRecords(w, response, records); err != nil {
+		return odintypes.RCODE_SERVFAIL, err
+	}
+
+	s.logger.Info("Zone transferred", "zone", zone.Name, "type", odintypes.TypeToString(question.Type), "client", w.RemoteAddr().String(), "records", len(records)-1)
+	return odintypes.RCODE_NOERROR, nil
+}
+
+var errMissingClientSerial = errors.New("IXFR query without SOA in the authority section")
+
+// incrementalRecords builds the answer to an IXFR query from the zone journal
+// (RFC 1995 4). Clients that are up to date, or that asked over UDP, only get the
+// current SOA. When the journal does not lead from the client's serial to the
+// current one the whole zone is sent as in an AXFR.
+func (s *Server) incrementalRecords(w responseWriter, request *odintypes.DNSRequest, zone *types.DBZone) ([]*odintypes.DNSRecord, error) {
+	var clientSOA *odintypes.SOAData
+	for _, record := range request.Authority {
+		if record.Type == odintypes.TYPE_SOA {
+			soa, err := odintypes.UnpackSOAData(record.RData)
+			if err != nil {
+				return nil, errMissingClientSerial
+			}
+			clientSOA = soa
+			break
+		}
+	}
+	if clientSOA == nil {
+		return nil, errMissingClientSerial
+	}
+
+	dbSOA, err := s.cacheDriver.GetZoneSOA(zone.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load SOA of %s: %w", zone.Name, err)
+	}
+	if dbSOA == nil {
+		return nil, fmt.Errorf("zone %s has no SOA record", zone.Name)
+	}
+	soa, err := wireRecord(*dbSOA)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SOA record of zone %s: %w", zone.Name, err)
+	}
+	current, err := odintypes.UnpackSOAData(soa.RData)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SOA record of zone %s: %w", zone.Name, err)
+	}
+
+	if !odintypes.SOASerialLess(clientSOA.Serial, current.Serial) || w.Network() != "tcp" {
+		// RFC 1995 2: a UDP reply that can not hold the changes only carries the SOA
+		return []*odintypes.DNSRecord{soa}, nil
+	}
+
+	entries, err := s.cacheDriver.GetZoneJournal(zone.ID, clientSOA.Serial)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load journal of %s: %w", zone.Name, err)
+	}
+
+	// the journal holds one change per serial, each has to start where the last one ended
+	records := []*odintypes.DNSRecord{soa}
+	serial, next := clientSOA.Serial, clientSOA.Serial
+	for _, entry := range entries {
+		if entry.SerialFrom != serial {
+			if entry.SerialFrom != next {
+				break
+			}
+			serial = next
+		}
+		next = entry.SerialTo
+		if entry.Type == "ALIAS" {
+			continue
+		}
+		record, err := wireRecord(types.DBRecord{Name: entry.Name, Type: entry.Type, Class: entry.Class, TTL: entry.TTL, RData: entry.RData})
+		if err != nil {
+			return nil, fmt.Errorf("invalid journal entry %d of zone %s: %w", entry.ID, zone.Name, err)
+		}
+		records = append(records, record)
+	}
+
+	if next != current.Serial {
+		s.logger.Info("Journal does not cover IXFR, sending full zone", "zone", zone.Name, "client_serial", clientSOA.Serial, "serial", current.Serial)
+		return s.fullZoneRecords(zone)
+	}
+	return append(records, soa), nil
+}
+
+// fullZoneRecords returns the whole zone enclosed in its apex SOA.
+func (s *Server) fullZoneRecords(zone *types.DBZone) ([]*odintypes.DNSRecord, error) {
+	soa, records, err := s.zoneRecords(zone)
+	if err != nil {
+		return nil, err
+	}
+	return append(append([]*odintypes.DNSRecord{soa}, records...), soa), nil
+}
+
+func (s *Server) refuseTransfer(w responseWriter, response *odintypes.DNSRequest, rcode uint8) (uint8, error) {
+	response.Header.Flags.RCode = rcode
+	if err := w.WriteResponse(response, parser.MaxTCPMessageSize); err != nil {
+		return rcode, err
+	}
+	return rcode, nil
+}
+
+// transferAllowed checks the client against the zone's transfer ACL. Zones without
+// ACL entries can not be transferred at all.
+func (s *Server) transferAllowed(zone *types.DBZone, w responseWriter) (bool, error) {
+	entries, err := s.cacheDriver.GetZoneTransferACL(zone.ID)
+	if err != nil {
+		return false, fmt.Errorf("failed to load transfer ACL of %s: %w", zone.Name, err)
+	}
+
+	networks := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		networks = append(networks, entry.Network)
+	}
+	nets, err := util.ParseClientNets(networks)
+	if err != nil {
+		return false, fmt.Errorf("invalid transfer ACL of %s: %w", zone.Name, err)
+	}
+	return util.ClientAllowed(nets, clientIP(w.RemoteAddr())), nil
+}
+
+// zoneRecords loads the apex SOA and all other records of zone in wire form.
+// ALIAS records only exist inside this server and are left out.
+func (s *Server) zoneRecords(zone *types.DBZone) (*odintypes.DNSRecord, []*odintypes.DNSRecord, error) {
+	_, dbRecords, err := s.cacheDriver.GetFullZoneById(zone.ID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load zone %s: %w", zone.Name, err)
+	}
+
+	var soa *odintypes.DNSRecord
+	records := make([]*odintypes.DNSRecord, 0, len(dbRecords))
+	for _, dbRecord := range dbRecords {
+		record, err := wireRecord(dbRecord)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid record %s in zone %s: %w", dbRecord.ID, zone.Name, err)
+		}
+		switch {
+		case record.Type == odintypes.TYPE_ALIAS:
+		case record.Type == odintypes.TYPE_SOA && strings.EqualFold(record.Name, zone.Name):
+			soa = record
+		default:
+			records = append(records, record)
+		}
+	}
+
+	if soa == nil {
+		return nil, nil, fmt.Errorf("zone %s has no SOA record", zone.Name)
+	}
+	return soa, records, nil
+}
+
+// wireRecord converts a stored record into its wire form.
+func wireRecord(dbRecord types.DBRecord) (*odintypes.DNSRecord, error) {
+	rtype, err := odintypes.StringToType(dbRecord.Type)
+	if err != nil {
+		return nil, err
+	}
+	rclass, err := odintypes.StringToClass(dbRecord.Class)
+	if err != nil {
+		return nil, err
+	}
+	rData, err := util.ConvertRDataStringToBytes(rtype, dbRecord.RData)
+	if err != nil {
+		return nil, err
+	}
+	return &odintypes.DNSRecord{
+		Name:  dbRecord.Name,
+		Type:  rtype,
+		Class: rclass,
+		TTL:   dbRecord.TTL,
+		RData: rData,
+	}, nil
+}
+
+// streamRecords sends records in as many messages as needed. Only the first
+// message repeats the question (RFC 5936 2.2.1).
+func (s *Server) streamRecords(w responseWriter, response *odintypes.DNSRequest, records []*odintypes.DNSRecord) error {
+	message := *response
+	message.Header.Flags.AA = true
+	message.Header.Flags.RCode = odintypes.RCODE_NOERROR
+	message.Answers = nil
+
+	size := 0
+	for i, record := range records {
+		message.Answers = append(message.Answers, record)
+		// owner and target names take their length plus two octets uncompressed
+		size += len(record.Name) + 2 + 10 + len(record.RData) + 2
+
+		if size < transferMessageSize && i < len(records)-1 {
+			continue
+		}
+		if err := w.WriteResponse(&message, parser.MaxTCPMessageSize); err != nil {
+			return fmt.Errorf("failed to send transfer message: %w", err)
+		}
+		message.Questions = nil
+		message.Answers = nil
+		size = 0
+	}
+	return nil
+}
+
+// journalPruneInterval is how often journal entries past JOURNAL_RETENTION are dropped.
+const journalPruneInterval = time.Hour
+
+// pruneJournal drops journal entries older than the configured retention, once at
+// startup and then periodically. Secondaries behind a pruned serial get an AXFR.
+func (s *Server) pruneJournal() {
+	ticker := time.NewTicker(journalPruneInterval)
+	defer ticker.Stop()
+
+	for {
+		removed, err := s.cacheDriver.PruneZoneJournal(time.Now().UTC().Add(-s.config.JOURNAL_RETENTION))
+		if err != nil {
+			s.logger.Error("Failed to prune zone journal", "error", err)
+		} else if removed > 0 {
+			s.logger.Info("Zone journal pruned", "entries", removed)
+		}
+		<-ticker.C
+	}
+}
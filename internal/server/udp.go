@@ -0,0 +1,64 @@
+package server
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/Unfield/Odin-DNS/internal/parser"
+	"github.com/Unfield/Odin-DNS/pkg/odintypes"
+)
+
+type udpResponseWriter struct {
+	conn       *net.UDPConn
+	clientAddr *net.UDPAddr
+}
+
+func (w *udpResponseWriter) RemoteAddr() net.Addr {
+	return w.clientAddr
+}
+
+func (w *udpResponseWriter) Network() string {
+	return "udp"
+}
+
+func (w *udpResponseWriter) WriteResponse(response *odintypes.DNSRequest, maxSize int) error {
+	binaryResponse, err := parser.PackResponseWithLimit(response, maxSize)
+	if err != nil {
+		return fmt.Errorf("Error packing DNS response: %w", err)
+	}
+	_, err = w.conn.WriteToUDP(binaryResponse, w.clientAddr)
+	if err != nil {
+		return fmt.Errorf("Error writing DNS response to UDP: %w", err)
+	}
+	return nil
+}
+
+func (s *Server) serveUDP() error {
+	addr, err := net.ResolveUDPAddr("udp", fmt.Sprintf("%s:%d", s.config.DNS_HOST, s.config.DNS_PORT))
+	if err != nil {
+		return fmt.Errorf("error resolving address: %w", err)
+	}
+
+	conn, err := net.ListenUDP("udp", addr)
+	if err != nil {
+		return fmt.Errorf("error listening on UDP port: %w", err)
+	}
+	defer conn.Close()
+
+	s.logger.Info("Odin DNS server is running", "protocol", "udp", "port", addr.Port)
+
+	buffer := make([]byte, max(s.config.BUFFER_SIZE, s.config.EDNS_UDP_PAYLOAD_SIZE))
+
+	for {
+		n, clientAddr, err := conn.ReadFromUDP(buffer)
+		if err != nil {
+			s.logger.Error("Error reading from UDP", "error", err)
+			continue
+		}
+
+		requestDataCopy := make([]byte, n)
+		copy(requestDataCopy, buffer[:n])
+
+		go s.handleRequest(&udpResponseWriter{conn: conn, clientAddr: clientAddr}, requestDataCopy)
+	}
+}
@@ -0,0 +1,444 @@
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/Unfield/Odin-DNS/internal/types"
+	"github.com/Unfield/Odin-DNS/internal/util"
+	"github.com/Unfield/Odin-DNS/pkg/odintypes"
+	gonanoid "github.com/matoous/go-nanoid/v2"
+)
+
+// updateEntry is a record of the zone while a dynamic update is applied to it.
+type updateEntry struct {
+	id     string
+	record *odintypes.DNSRecord
+	// stored is nil for records added by the update, they get an ID on write
+	stored  *types.DBRecord
+	changed bool
+}
+
+// zoneUpdate holds the records of a zone and tracks the changes of a dynamic
+// update until they are written in one ApplyZoneChange.
+type zoneUpdate struct {
+	zone    *types.DBZone
+	class   uint16
+	entries []*updateEntry
+	removed []types.DBRecord
+}
+
+// handleUpdate processes a dynamic update (RFC 2136) and answers with its outcome.
+// It returns the RCODE for the metrics.
+func (s *Server) handleUpdate(w responseWriter, request *odintypes.DNSRequest, response *odintypes.DNSRequest, maxSize int) (uint8, error) {
+	rcode, err := s.applyUpdate(w, request)
+	response.Header.Flags.RCode = rcode
+
+	if writeErr := w.WriteResponse(response, maxSize); writeErr != nil && err == nil {
+		err = writeErr
+	}
+	return rcode, err
+}
+
+// applyUpdate checks the zone section and the prerequisites of an update against
+// the current zone and applies the update section in order (RFC 2136 3). All
+// changes are written in one transaction that also bumps the SOA serial, an
+// update that changes nothing leaves the serial alone.
+func (s *Server) applyUpdate(w responseWriter, request *odintypes.DNSRequest) (uint8, error) {
+	if len(request.Questions) != 1 || request.Questions[0].Type != odintypes.TYPE_SOA {
+		return odintypes.RCODE_FORMERR, nil
+	}
+	zoneSection := request.Questions[0]
+	// all our zones are in class IN
+	if zoneSection.Class != odintypes.CLASS_IN {
+		return odintypes.RCODE_NOTAUTH, nil
+	}
+
+	zone, err := s.cacheDriver.FindZoneForName(zoneSection.Name)
+	if err != nil {
+		return odintypes.RCODE_SERVFAIL, err
+	}
+	if zone == nil || !strings.EqualFold(zone.Name, strings.TrimSuffix(zoneSection.Name, ".")) {
+		return odintypes.RCODE_NOTAUTH, nil
+	}
+	// updates are not forwarded to the primaries of a secondary zone (RFC 2136 6)
+	if zone.IsSecondary() || !util.ClientAllowed(s.updateClients, clientIP(w.RemoteAddr())) {
+		return odintypes.RCODE_REFUSED, nil
+	}
+
+	s.updateMu.Lock()
+	defer s.updateMu.Unlock()
+
+	update, err := s.loadZoneUpdate(zone, zoneSection.Class)
+	if err != nil {
+		return odintypes.RCODE_SERVFAIL, err
+	}
+	if rcode := update.checkPrerequisites(request.Answers); rcode != odintypes.RCODE_NOERROR {
+		return rcode, nil
+	}
+	if rcode := update.prescan(request.Authority); rcode != odintypes.RCODE_NOERROR {
+		return rcode, nil
+	}
+	for _, record := range request.Authority {
+		update.apply(record)
+	}
+
+	deleted, added, err := update.changes()
+	if err != nil {
+		return odintypes.RCODE_SERVFAIL, err
+	}
+	if len(deleted) == 0 && len(added) == 0 {
+		return odintypes.RCODE_NOERROR, nil
+	}
+	if _, err := s.cacheDriver.ApplyZoneChange(zone.ID, deleted, added); err != nil {
+		return odintypes.RCODE_SERVFAIL, fmt.Errorf("failed to apply update: %w", err)
+	}
+
+	s.logger.Info("Zone updated", "zone", zone.Name, "client", w.RemoteAddr().String(), "deleted", len(deleted), "added", len(added))
+	return odintypes.RCODE_NOERROR, nil
+}
+
+func (s *Server) loadZoneUpdate(zone *types.DBZone, class uint16) (*zoneUpdate, error) {
+	dbRecords, err := s.cacheDriver.GetZoneEntries(zone.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load zone %s: %w", zone.Name, err)
+	}
+
+	update := &zoneUpdate{zone: zone, class: class}
+	for i := range dbRecords {
+		record, err := wireRecord(dbRecords[i])
+		if err != nil {
+			return nil, fmt.Errorf("invalid record %s in zone %s: %w", dbRecords[i].ID, zone.Name, err)
+		}
+		update.entries = append(update.entries, &updateEntry{id: dbRecords[i].ID, record: record, stored: &dbRecords[i]})
+	}
+	return update, nil
+}
+
+// checkPrerequisites evaluates the prerequisite section (RFC 2136 3.2). RRsets
+// that must exist with exact values are collected first and compared as a whole.
+func (u *zoneUpdate) checkPrerequisites(prerequisites []*odintypes.DNSRecord) uint8 {
+	type rrsetKey struct {
+		name  string
+		rtype uint16
+	}
+	var keys []rrsetKey
+	expected := make(map[rrsetKey][]*odintypes.DNSRecord)
+
+	for _, record := range prerequisites {
+		if record.TTL != 0 {
+			return odintypes.RCODE_FORMERR
+		}
+		if !util.IsSubdomain(record.Name, u.zone.Name) {
+			return odintypes.RCODE_NOTZONE
+		}
+
+		switch record.Class {
+		case odintypes.CLASS_ANY:
+			if len(record.RData) != 0 {
+				return odintypes.RCODE_FORMERR
+			}
+			if record.Type == odintypes.TYPE_ANY {
+				if len(u.find(record.Name, odintypes.TYPE_ANY)) == 0 {
+					return odintypes.RCODE_NXDOMAIN
+				}
+			} else if len(u.find(record.Name, record.Type)) == 0 {
+				return odintypes.RCODE_NXRRSET
+			}
+		case odintypes.CLASS_NONE:
+			if len(record.RData) != 0 {
+				return odintypes.RCODE_FORMERR
+			}
+			if record.Type == odintypes.TYPE_ANY {
+				if len(u.find(record.Name, odintypes.TYPE_ANY)) != 0 {
+					return odintypes.RCODE_YXDOMAIN
+				}
+			} else if len(u.find(record.Name, record.Type)) != 0 {
+				return odintypes.RCODE_YXRRSET
+			}
+		case u.class:
+			if isMetaType(record.Type) {
+				return odintypes.RCODE_FORMERR
+			}
+			key := rrsetKey{name: strings.ToLower(strings.TrimSuffix(record.Name, ".")), rtype: record.Type}
+			if _, ok := expected[key]; !ok {
+				keys = append(keys, key)
+			}
+			expected[key] = append(expected[key], record)
+		default:
+			return odintypes.RCODE_FORMERR
+		}
+	}
+
+	for _, key := range keys {
+		if !sameRRset(u.find(key.name, key.rtype), expected[key]) {
+			return odintypes.RCODE_NXRRSET
+		}
+	}
+	return odintypes.RCODE_NOERROR
+}
+
+// prescan rejects an update section that can not be applied as a whole before
+// anything is changed (RFC 2136 3.4.1).
+func (u *zoneUpdate) prescan(updates []*odintypes.DNSRecord) uint8 {
+	for _, record := range updates {
+		if !util.IsSubdomain(record.Name, u.zone.Name) {
+			return odintypes.RCODE_NOTZONE
+		}
+
+		switch record.Class {
+		case u.class:
+			if isMetaType(record.Type) || record.Type == odintypes.TYPE_ALIAS {
+				return odintypes.RCODE_FORMERR
+			}
+			// the record has to survive the trip through its stored presentation form
+			rData := util.ConvertRDataBytesToString(record.Type, record.RData)
+			if rData == "" {
+				return odintypes.RCODE_FORMERR
+			}
+			if _, err := util.ConvertRDataStringToBytes(record.Type, rData); err != nil {
+				return odintypes.RCODE_FORMERR
+			}
+		case odintypes.CLASS_ANY:
+			if record.TTL != 0 || len(record.RData) != 0 || isMetaType(record.Type) && record.Type != odintypes.TYPE_ANY {
+				return odintypes.RCODE_FORMERR
+			}
+		case odintypes.CLASS_NONE:
+			if record.TTL != 0 || isMetaType(record.Type) {
+				return odintypes.RCODE_FORMERR
+			}
+		default:
+			return odintypes.RCODE_FORMERR
+		}
+	}
+	return odintypes.RCODE_NOERROR
+}
+
+// apply performs one record of the update section (RFC 2136 3.4.2). Changes that
+// would remove the SOA or the last apex NS, or mix a CNAME with other data, are
+// silently ignored as the RFC demands.
+func (u *zoneUpdate) apply(record *odintypes.DNSRecord) {
+	apex := strings.EqualFold(strings.TrimSuffix(record.Name, "."), u.zone.Name)
+
+	switch record.Class {
+	case u.class:
+		u.add(record, apex)
+
+	case odintypes.CLASS_ANY:
+		for _, entry := range u.find(record.Name, record.Type) {
+			if apex && (entry.record.Type == odintypes.TYPE_SOA || entry.record.Type == odintypes.TYPE_NS) {
+				continue
+			}
+			u.remove(entry)
+		}
+
+	case odintypes.CLASS_NONE:
+		if record.Type == odintypes.TYPE_SOA {
+			return
+		}
+		if apex && record.Type == odintypes.TYPE_NS && len(u.find(record.Name, odintypes.TYPE_NS)) <= 1 {
+			return
+		}
+		for _, entry := range u.find(record.Name, record.Type) {
+			if sameRData(record.Type, entry.record.RData, record.RData) {
+				u.remove(entry)
+			}
+		}
+	}
+}
+
+func (u *zoneUpdate) add(record *odintypes.DNSRecord, apex bool) {
+	if record.Type == odintypes.TYPE_SOA {
+		// the serial is computed on write, a newer one only lets the other fields through
+		current := u.find(record.Name, odintypes.TYPE_SOA)
+		if !apex || len(current) == 0 {
+			return
+		}
+		currentSOA, err := odintypes.UnpackSOAData(current[0].record.RData)
+		if err != nil {
+			return
+		}
+		newSOA, err := odintypes.UnpackSOAData(record.RData)
+		if err != nil || !odintypes.SOASerialLess(currentSOA.Serial, newSOA.Serial) {
+			return
+		}
+		u.replace(current[0], record)
+		return
+	}
+
+	for _, entry := range u.find(record.Name, odintypes.TYPE_ANY) {
+		isCNAME := entry.record.Type == odintypes.TYPE_CNAME
+		if isCNAME != (record.Type == odintypes.TYPE_CNAME) {
+			return
+		}
+		if isCNAME {
+			// a name holds a single CNAME, a new one replaces it
+			if entry.record.TTL != record.TTL || !sameRData(record.Type, entry.record.RData, record.RData) {
+				u.replace(entry, record)
+			}
+			return
+		}
+	}
+
+	for _, entry := range u.find(record.Name, record.Type) {
+		if sameRData(record.Type, entry.record.RData, record.RData) {
+			if entry.record.TTL != record.TTL {
+				u.replace(entry, record)
+			}
+			return
+		}
+	}
+
+	// the constraints the API enforces on DNAME and ALIAS owners hold for updates too
+	records := u.records()
+	name, rtype := strings.TrimSuffix(record.Name, "."), odintypes.TypeToString(record.Type)
+	if util.DNAMEConflict(records, "", name, rtype) != "" || util.AliasConflict(records, "", name, rtype) != "" {
+		return
+	}
+
+	u.entries = append(u.entries, &updateEntry{record: record})
+}
+
+// records lists the owner and type of the current zone records in the form the
+// conflict checks of the util package take.
+func (u *zoneUpdate) records() []types.DBRecord {
+	records := make([]types.DBRecord, 0, len(u.entries))
+	for _, entry := range u.entries {
+		records = append(records, types.DBRecord{
+			ID:   entry.id,
+			Name: strings.TrimSuffix(entry.record.Name, "."),
+			Type: odintypes.TypeToString(entry.record.Type),
+		})
+	}
+	return records
+}
+
+// replace swaps the content of entry for record, keeping its ID so the change is
+// written as an update of the stored row.
+func (u *zoneUpdate) replace(entry *updateEntry, record *odintypes.DNSRecord) {
+	entry.record = record
+	entry.changed = true
+}
+
+func (u *zoneUpdate) remove(entry *updateEntry) {
+	for i, current := range u.entries {
+		if current == entry {
+			u.entries = append(u.entries[:i], u.entries[i+1:]...)
+			break
+		}
+	}
+	if entry.stored != nil {
+		u.removed = append(u.removed, *entry.stored)
+	}
+}
+
+// find returns the records of the zone with the given owner and type, TYPE_ANY
+// matches every type.
+func (u *zoneUpdate) find(name string, rtype uint16) []*updateEntry {
+	name = strings.TrimSuffix(name, ".")
+	var found []*updateEntry
+	for _, entry := range u.entries {
+		if !strings.EqualFold(entry.record.Name, name) {
+			continue
+		}
+		if rtype == odintypes.TYPE_ANY || entry.record.Type == rtype {
+			found = append(found, entry)
+		}
+	}
+	return found
+}
+
+// changes returns the records to delete and add for ApplyZoneChange. A changed
+// stored record is deleted and added under its ID, which updates it in place.
+func (u *zoneUpdate) changes() ([]types.DBRecord, []types.DBRecord, error) {
+	deleted := u.removed
+	var added []types.DBRecord
+	for _, entry := range u.entries {
+		if entry.stored != nil && !entry.changed {
+			continue
+		}
+		if entry.stored != nil {
+			deleted = append(deleted, *entry.stored)
+		} else {
+			id, err := gonanoid.New()
+			if err != nil {
+				return nil, nil, err
+			}
+			entry.id = id
+		}
+
+		rData := util.ConvertRDataBytesToString(entry.record.Type, entry.record.RData)
+		if rData == "" {
+			return nil, nil, fmt.Errorf("invalid %s record for %s", odintypes.TypeToString(entry.record.Type), entry.record.Name)
+		}
+		added = append(added, types.DBRecord{
+			ID:     entry.id,
+			ZoneID: u.zone.ID,
+			Name:   strings.TrimSuffix(entry.record.Name, "."),
+			Type:   odintypes.TypeToString(entry.record.Type),
+			Class:  odintypes.ClassToString(u.class),
+			TTL:    entry.record.TTL,
+			RData:  rData,
+		})
+	}
+	return deleted, added, nil
+}
+
+// isMetaType reports whether rtype only exists in queries and can not be stored.
+func isMetaType(rtype uint16) bool {
+	return rtype == odintypes.TYPE_OPT || rtype >= odintypes.TYPE_IXFR && rtype <= odintypes.TYPE_ANY
+}
+
+// sameRRset reports whether both sets hold the same data, ignoring TTLs and order.
+func sameRRset(current []*updateEntry, expected []*odintypes.DNSRecord) bool {
+	if len(current) == 0 {
+		return false
+	}
+	for _, entry := range current {
+		if !containsRData(expected, entry.record) {
+			return false
+		}
+	}
+	for _, record := range expected {
+		found := false
+		for _, entry := range current {
+			if sameRData(record.Type, entry.record.RData, record.RData) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func containsRData(records []*odintypes.DNSRecord, record *odintypes.DNSRecord) bool {
+	for _, candidate := range records {
+		if sameRData(record.Type, candidate.RData, record.RData) {
+			return true
+		}
+	}
+	return false
+}
+
+// sameRData compares RData in the internal form, names inside it are compared
+// without regard to case or a trailing dot.
+func sameRData(rtype uint16, a []byte, b []byte) bool {
+	fixed := -1
+	switch rtype {
+	case odintypes.TYPE_CNAME, odintypes.TYPE_NS, odintypes.TYPE_PTR, odintypes.TYPE_DNAME, odintypes.TYPE_ALIAS:
+		fixed = 0
+	case odintypes.TYPE_MX:
+		fixed = 2
+	case odintypes.TYPE_SRV:
+		fixed = 6
+	}
+	if fixed < 0 || len(a) < fixed || len(b) < fixed {
+		return bytes.Equal(a, b)
+	}
+	return bytes.Equal(a[:fixed], b[:fixed]) &&
+		strings.EqualFold(strings.TrimSuffix(string(a[fixed:]), "."), strings.TrimSuffix(string(b[fixed:]), "."))
+}
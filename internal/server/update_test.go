@@ -0,0 +1,472 @@
+package server
+
+import (
+	"fmt"
+	"net"
+	"slices"
+	"testing"
+
+	"github.com/Unfield/Odin-DNS/internal/types"
+	"github.com/Unfield/Odin-DNS/internal/util"
+	"github.com/Unfield/Odin-DNS/pkg/odintypes"
+)
+
+// newUpdateTestServer returns a server holding the primary zone example.com and
+// the secondary zone example.org.
+func newUpdateTestServer(t *testing.T) (*Server, *memStore) {
+	t.Helper()
+
+	store := &memStore{}
+	store.addZone(types.DBZone{ID: "zone-example", Name: "example.com", Kind: types.ZoneKindPrimary})
+	store.addRecord("zone-example", "example.com", "SOA", 3600, "ns1.example.com hostmaster.example.com 10 3600 900 604800 300")
+	store.addRecord("zone-example", "example.com", "NS", 3600, "ns1.example.com")
+	store.addRecord("zone-example", "www.example.com", "A", 300, "192.0.2.1")
+	store.addRecord("zone-example", "mail.example.com", "CNAME", 300, "www.example.com")
+	store.addZone(types.DBZone{ID: "zone-secondary", Name: "example.org", Kind: types.ZoneKindSecondary, Primaries: "192.0.2.100"})
+
+	server, _ := newTestServer(t, store)
+	return server, store
+}
+
+// newTestZoneUpdate returns an update of example.com loaded from the store, with
+// the records in stored added to the zone first.
+func newTestZoneUpdate(t *testing.T, stored ...types.DBRecord) *zoneUpdate {
+	t.Helper()
+
+	server, store := newUpdateTestServer(t)
+	for _, record := range stored {
+		store.addRecord("zone-example", record.Name, record.Type, record.TTL, record.RData)
+	}
+	zone, err := store.GetZone("zone-example")
+	if err != nil {
+		t.Fatal(err)
+	}
+	update, err := server.loadZoneUpdate(zone, odintypes.CLASS_IN)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return update
+}
+
+// updateRecord builds a record of a prerequisite or update section. An empty
+// rData leaves the RDATA empty, as used with the classes ANY and NONE.
+func updateRecord(t *testing.T, name string, rtype uint16, class uint16, ttl uint32, rData string) *odintypes.DNSRecord {
+	t.Helper()
+
+	record := &odintypes.DNSRecord{Name: name, Type: rtype, Class: class, TTL: ttl}
+	if rData != "" {
+		packed, err := util.ConvertRDataStringToBytes(rtype, rData)
+		if err != nil {
+			t.Fatalf("invalid test RDATA %q: %v", rData, err)
+		}
+		record.RData = packed
+	}
+	return record
+}
+
+// zoneContents lists the records of the update as "name type ttl rdata", sorted.
+func zoneContents(update *zoneUpdate) []string {
+	var contents []string
+	for _, entry := range update.entries {
+		contents = append(contents, fmt.Sprintf("%s %s %d %s", entry.record.Name, odintypes.TypeToString(entry.record.Type), entry.record.TTL,
+			util.ConvertRDataBytesToString(entry.record.Type, entry.record.RData)))
+	}
+	slices.Sort(contents)
+	return contents
+}
+
+const (
+	testSOAEntry   = "example.com SOA 3600 ns1.example.com hostmaster.example.com 10 3600 900 604800 300"
+	testNSEntry    = "example.com NS 3600 ns1.example.com"
+	testWWWEntry   = "www.example.com A 300 192.0.2.1"
+	testCNAMEEntry = "mail.example.com CNAME 300 www.example.com"
+)
+
+func TestUpdateCheckPrerequisites(t *testing.T) {
+	const (
+		IN   = odintypes.CLASS_IN
+		ANY  = odintypes.CLASS_ANY
+		NONE = odintypes.CLASS_NONE
+	)
+	type rr struct {
+		name  string
+		rtype uint16
+		class uint16
+		ttl   uint32
+		rData string
+	}
+
+	tests := []struct {
+		name          string
+		prerequisites []rr
+		want          uint8
+	}{
+		{name: "none", want: odintypes.RCODE_NOERROR},
+		{name: "TTL set", prerequisites: []rr{{"www.example.com", odintypes.TYPE_ANY, ANY, 300, ""}}, want: odintypes.RCODE_FORMERR},
+		{name: "outside the zone", prerequisites: []rr{{"www.example.net", odintypes.TYPE_ANY, ANY, 0, ""}}, want: odintypes.RCODE_NOTZONE},
+		{name: "unknown class", prerequisites: []rr{{"www.example.com", odintypes.TYPE_A, 3, 0, ""}}, want: odintypes.RCODE_FORMERR},
+
+		{name: "name in use", prerequisites: []rr{{"www.example.com", odintypes.TYPE_ANY, ANY, 0, ""}}, want: odintypes.RCODE_NOERROR},
+		{name: "name not in use", prerequisites: []rr{{"ftp.example.com", odintypes.TYPE_ANY, ANY, 0, ""}}, want: odintypes.RCODE_NXDOMAIN},
+		{name: "RRset exists", prerequisites: []rr{{"www.example.com", odintypes.TYPE_A, ANY, 0, ""}}, want: odintypes.RCODE_NOERROR},
+		{name: "RRset missing", prerequisites: []rr{{"www.example.com", odintypes.TYPE_AAAA, ANY, 0, ""}}, want: odintypes.RCODE_NXRRSET},
+		{name: "class ANY with RDATA", prerequisites: []rr{{"www.example.com", odintypes.TYPE_A, ANY, 0, "192.0.2.1"}}, want: odintypes.RCODE_FORMERR},
+
+		{name: "name must not be in use", prerequisites: []rr{{"ftp.example.com", odintypes.TYPE_ANY, NONE, 0, ""}}, want: odintypes.RCODE_NOERROR},
+		{name: "name unexpectedly in use", prerequisites: []rr{{"www.example.com", odintypes.TYPE_ANY, NONE, 0, ""}}, want: odintypes.RCODE_YXDOMAIN},
+		{name: "RRset must not exist", prerequisites: []rr{{"www.example.com", odintypes.TYPE_AAAA, NONE, 0, ""}}, want: odintypes.RCODE_NOERROR},
+		{name: "RRset unexpectedly exists", prerequisites: []rr{{"www.example.com", odintypes.TYPE_A, NONE, 0, ""}}, want: odintypes.RCODE_YXRRSET},
+		{name: "CNAME counts as data", prerequisites: []rr{{"mail.example.com", odintypes.TYPE_ANY, NONE, 0, ""}}, want: odintypes.RCODE_YXDOMAIN},
+
+		{name: "RRset with values", prerequisites: []rr{{"www.example.com", odintypes.TYPE_A, IN, 0, "192.0.2.1"}}, want: odintypes.RCODE_NOERROR},
+		{name: "RRset with other values", prerequisites: []rr{{"www.example.com", odintypes.TYPE_A, IN, 0, "192.0.2.9"}}, want: odintypes.RCODE_NXRRSET},
+		{name: "RRset with more values", prerequisites: []rr{
+			{"www.example.com", odintypes.TYPE_A, IN, 0, "192.0.2.1"},
+			{"www.example.com", odintypes.TYPE_A, IN, 0, "192.0.2.2"},
+		}, want: odintypes.RCODE_NXRRSET},
+		{name: "RRset with names in other case", prerequisites: []rr{{"MAIL.example.com", odintypes.TYPE_CNAME, IN, 0, "WWW.Example.com"}}, want: odintypes.RCODE_NOERROR},
+		{name: "RRset of a meta type", prerequisites: []rr{{"www.example.com", odintypes.TYPE_ANY, IN, 0, ""}}, want: odintypes.RCODE_FORMERR},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var prerequisites []*odintypes.DNSRecord
+			for _, p := range test.prerequisites {
+				prerequisites = append(prerequisites, updateRecord(t, p.name, p.rtype, p.class, p.ttl, p.rData))
+			}
+			if got := newTestZoneUpdate(t).checkPrerequisites(prerequisites); got != test.want {
+				t.Errorf("RCODE %d, want %d", got, test.want)
+			}
+		})
+	}
+}
+
+func TestUpdatePrescan(t *testing.T) {
+	tests := []struct {
+		name   string
+		record *odintypes.DNSRecord
+		want   uint8
+	}{
+		{name: "add", record: updateRecord(t, "ftp.example.com", odintypes.TYPE_A, odintypes.CLASS_IN, 300, "192.0.2.5"), want: odintypes.RCODE_NOERROR},
+		{name: "add outside the zone", record: updateRecord(t, "ftp.example.net", odintypes.TYPE_A, odintypes.CLASS_IN, 300, "192.0.2.5"), want: odintypes.RCODE_NOTZONE},
+		{name: "add a meta type", record: updateRecord(t, "ftp.example.com", odintypes.TYPE_AXFR, odintypes.CLASS_IN, 300, ""), want: odintypes.RCODE_FORMERR},
+		{name: "add an ALIAS", record: updateRecord(t, "example.com", odintypes.TYPE_ALIAS, odintypes.CLASS_IN, 300, "www.example.net"), want: odintypes.RCODE_FORMERR},
+		{name: "add malformed RDATA", record: &odintypes.DNSRecord{Name: "ftp.example.com", Type: odintypes.TYPE_A, Class: odintypes.CLASS_IN, TTL: 300, RData: []byte{192, 0, 2}}, want: odintypes.RCODE_FORMERR},
+		{name: "delete an RRset", record: updateRecord(t, "www.example.com", odintypes.TYPE_A, odintypes.CLASS_ANY, 0, ""), want: odintypes.RCODE_NOERROR},
+		{name: "delete a name", record: updateRecord(t, "www.example.com", odintypes.TYPE_ANY, odintypes.CLASS_ANY, 0, ""), want: odintypes.RCODE_NOERROR},
+		{name: "delete an RRset with TTL", record: updateRecord(t, "www.example.com", odintypes.TYPE_A, odintypes.CLASS_ANY, 300, ""), want: odintypes.RCODE_FORMERR},
+		{name: "delete an RRset with RDATA", record: updateRecord(t, "www.example.com", odintypes.TYPE_A, odintypes.CLASS_ANY, 0, "192.0.2.1"), want: odintypes.RCODE_FORMERR},
+		{name: "delete a meta type", record: updateRecord(t, "www.example.com", odintypes.TYPE_AXFR, odintypes.CLASS_ANY, 0, ""), want: odintypes.RCODE_FORMERR},
+		{name: "delete a record", record: updateRecord(t, "www.example.com", odintypes.TYPE_A, odintypes.CLASS_NONE, 0, "192.0.2.1"), want: odintypes.RCODE_NOERROR},
+		{name: "delete a record with TTL", record: updateRecord(t, "www.example.com", odintypes.TYPE_A, odintypes.CLASS_NONE, 300, "192.0.2.1"), want: odintypes.RCODE_FORMERR},
+		{name: "delete a record of a meta type", record: updateRecord(t, "www.example.com", odintypes.TYPE_ANY, odintypes.CLASS_NONE, 0, ""), want: odintypes.RCODE_FORMERR},
+		{name: "unknown class", record: updateRecord(t, "www.example.com", odintypes.TYPE_A, 3, 0, ""), want: odintypes.RCODE_FORMERR},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := newTestZoneUpdate(t).prescan([]*odintypes.DNSRecord{test.record}); got != test.want {
+				t.Errorf("RCODE %d, want %d", got, test.want)
+			}
+		})
+	}
+}
+
+func TestUpdateApply(t *testing.T) {
+	tests := []struct {
+		name string
+		// stored are records of the zone besides the common ones
+		stored  []types.DBRecord
+		updates []*odintypes.DNSRecord
+		want    []string
+	}{
+		{
+			name:    "add to an RRset",
+			updates: []*odintypes.DNSRecord{updateRecord(t, "www.example.com", odintypes.TYPE_A, odintypes.CLASS_IN, 300, "192.0.2.2")},
+			want:    []string{testSOAEntry, testNSEntry, testCNAMEEntry, testWWWEntry, "www.example.com A 300 192.0.2.2"},
+		},
+		{
+			name:    "add an existing record with a new TTL",
+			updates: []*odintypes.DNSRecord{updateRecord(t, "www.example.com", odintypes.TYPE_A, odintypes.CLASS_IN, 60, "192.0.2.1")},
+			want:    []string{testSOAEntry, testNSEntry, testCNAMEEntry, "www.example.com A 60 192.0.2.1"},
+		},
+		{
+			name:    "CNAME next to other data is ignored",
+			updates: []*odintypes.DNSRecord{updateRecord(t, "www.example.com", odintypes.TYPE_CNAME, odintypes.CLASS_IN, 300, "ftp.example.com")},
+			want:    []string{testSOAEntry, testNSEntry, testCNAMEEntry, testWWWEntry},
+		},
+		{
+			name:    "other data next to a CNAME is ignored",
+			updates: []*odintypes.DNSRecord{updateRecord(t, "mail.example.com", odintypes.TYPE_A, odintypes.CLASS_IN, 300, "192.0.2.3")},
+			want:    []string{testSOAEntry, testNSEntry, testCNAMEEntry, testWWWEntry},
+		},
+		{
+			name:    "a new CNAME replaces the old one",
+			updates: []*odintypes.DNSRecord{updateRecord(t, "mail.example.com", odintypes.TYPE_CNAME, odintypes.CLASS_IN, 300, "mx.example.net")},
+			want:    []string{testSOAEntry, testNSEntry, "mail.example.com CNAME 300 mx.example.net", testWWWEntry},
+		},
+		{
+			name: "CNAME after its name was cleared",
+			updates: []*odintypes.DNSRecord{
+				updateRecord(t, "www.example.com", odintypes.TYPE_ANY, odintypes.CLASS_ANY, 0, ""),
+				updateRecord(t, "www.example.com", odintypes.TYPE_CNAME, odintypes.CLASS_IN, 300, "ftp.example.com"),
+			},
+			want: []string{testSOAEntry, testNSEntry, testCNAMEEntry, "www.example.com CNAME 300 ftp.example.com"},
+		},
+		{
+			name:    "A next to an ALIAS is ignored",
+			stored:  []types.DBRecord{{Name: "ftp.example.com", Type: "ALIAS", TTL: 300, RData: "cdn.example.net"}},
+			updates: []*odintypes.DNSRecord{updateRecord(t, "ftp.example.com", odintypes.TYPE_A, odintypes.CLASS_IN, 300, "192.0.2.5")},
+			want:    []string{testSOAEntry, testNSEntry, testCNAMEEntry, testWWWEntry, "ftp.example.com ALIAS 300 cdn.example.net"},
+		},
+		{
+			name: "data below a DNAME is ignored",
+			updates: []*odintypes.DNSRecord{
+				updateRecord(t, "legacy.example.com", odintypes.TYPE_DNAME, odintypes.CLASS_IN, 300, "example.net"),
+				updateRecord(t, "host.legacy.example.com", odintypes.TYPE_A, odintypes.CLASS_IN, 300, "192.0.2.5"),
+			},
+			want: []string{testSOAEntry, testNSEntry, testCNAMEEntry, testWWWEntry, "legacy.example.com DNAME 300 example.net"},
+		},
+		{
+			name:    "DNAME above other names is ignored",
+			updates: []*odintypes.DNSRecord{updateRecord(t, "example.com", odintypes.TYPE_DNAME, odintypes.CLASS_IN, 300, "example.net")},
+			want:    []string{testSOAEntry, testNSEntry, testCNAMEEntry, testWWWEntry},
+		},
+		{
+			name:    "the last apex NS is kept",
+			updates: []*odintypes.DNSRecord{updateRecord(t, "example.com", odintypes.TYPE_NS, odintypes.CLASS_NONE, 0, "ns1.example.com")},
+			want:    []string{testSOAEntry, testNSEntry, testCNAMEEntry, testWWWEntry},
+		},
+		{
+			name:    "the apex NS RRset is kept",
+			updates: []*odintypes.DNSRecord{updateRecord(t, "example.com", odintypes.TYPE_NS, odintypes.CLASS_ANY, 0, "")},
+			want:    []string{testSOAEntry, testNSEntry, testCNAMEEntry, testWWWEntry},
+		},
+		{
+			name:    "clearing the apex keeps SOA and NS",
+			updates: []*odintypes.DNSRecord{updateRecord(t, "example.com", odintypes.TYPE_ANY, odintypes.CLASS_ANY, 0, "")},
+			want:    []string{testSOAEntry, testNSEntry, testCNAMEEntry, testWWWEntry},
+		},
+		{
+			name: "an apex NS with another one left is deleted",
+			updates: []*odintypes.DNSRecord{
+				updateRecord(t, "example.com", odintypes.TYPE_NS, odintypes.CLASS_IN, 3600, "ns2.example.net"),
+				updateRecord(t, "example.com", odintypes.TYPE_NS, odintypes.CLASS_NONE, 0, "ns1.example.com"),
+			},
+			want: []string{testSOAEntry, "example.com NS 3600 ns2.example.net", testCNAMEEntry, testWWWEntry},
+		},
+		{
+			name:    "SOA with an older serial is ignored",
+			updates: []*odintypes.DNSRecord{updateRecord(t, "example.com", odintypes.TYPE_SOA, odintypes.CLASS_IN, 3600, "ns2.example.com hostmaster.example.com 9 3600 900 604800 300")},
+			want:    []string{testSOAEntry, testNSEntry, testCNAMEEntry, testWWWEntry},
+		},
+		{
+			name:    "SOA with the same serial is ignored",
+			updates: []*odintypes.DNSRecord{updateRecord(t, "example.com", odintypes.TYPE_SOA, odintypes.CLASS_IN, 3600, "ns2.example.com hostmaster.example.com 10 3600 900 604800 300")},
+			want:    []string{testSOAEntry, testNSEntry, testCNAMEEntry, testWWWEntry},
+		},
+		{
+			name:    "SOA with a newer serial replaces the old one",
+			updates: []*odintypes.DNSRecord{updateRecord(t, "example.com", odintypes.TYPE_SOA, odintypes.CLASS_IN, 3600, "ns2.example.com hostmaster.example.com 11 3600 900 604800 300")},
+			want:    []string{"example.com SOA 3600 ns2.example.com hostmaster.example.com 11 3600 900 604800 300", testNSEntry, testCNAMEEntry, testWWWEntry},
+		},
+		{
+			name:    "SOA below the apex is ignored",
+			updates: []*odintypes.DNSRecord{updateRecord(t, "www.example.com", odintypes.TYPE_SOA, odintypes.CLASS_IN, 3600, "ns2.example.com hostmaster.example.com 11 3600 900 604800 300")},
+			want:    []string{testSOAEntry, testNSEntry, testCNAMEEntry, testWWWEntry},
+		},
+		{
+			name:    "the SOA is never deleted",
+			updates: []*odintypes.DNSRecord{updateRecord(t, "example.com", odintypes.TYPE_SOA, odintypes.CLASS_NONE, 0, "ns1.example.com hostmaster.example.com 10 3600 900 604800 300")},
+			want:    []string{testSOAEntry, testNSEntry, testCNAMEEntry, testWWWEntry},
+		},
+		{
+			name:    "delete a record",
+			updates: []*odintypes.DNSRecord{updateRecord(t, "www.example.com", odintypes.TYPE_A, odintypes.CLASS_NONE, 0, "192.0.2.1")},
+			want:    []string{testSOAEntry, testNSEntry, testCNAMEEntry},
+		},
+		{
+			name:    "delete a name",
+			updates: []*odintypes.DNSRecord{updateRecord(t, "mail.example.com", odintypes.TYPE_ANY, odintypes.CLASS_ANY, 0, "")},
+			want:    []string{testSOAEntry, testNSEntry, testWWWEntry},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			update := newTestZoneUpdate(t, test.stored...)
+			for _, record := range test.updates {
+				update.apply(record)
+			}
+
+			want := slices.Clone(test.want)
+			slices.Sort(want)
+			if got := zoneContents(update); !slices.Equal(got, want) {
+				t.Errorf("zone holds\n%q\nwant\n%q", got, want)
+			}
+		})
+	}
+}
+
+func TestUpdateChanges(t *testing.T) {
+	update := newTestZoneUpdate(t)
+	update.apply(updateRecord(t, "www.example.com", odintypes.TYPE_A, odintypes.CLASS_IN, 60, "192.0.2.1"))
+	update.apply(updateRecord(t, "ftp.example.com", odintypes.TYPE_A, odintypes.CLASS_IN, 300, "192.0.2.5"))
+	update.apply(updateRecord(t, "mail.example.com", odintypes.TYPE_ANY, odintypes.CLASS_ANY, 0, ""))
+
+	deleted, added, err := update.changes()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var deletedIDs []string
+	for _, record := range deleted {
+		deletedIDs = append(deletedIDs, record.ID)
+	}
+	slices.Sort(deletedIDs)
+	// the CNAME is gone, the A record is deleted and added again with its new TTL
+	if want := []string{"record-2", "record-3"}; !slices.Equal(deletedIDs, want) {
+		t.Errorf("deleted %q, want %q", deletedIDs, want)
+	}
+
+	if len(added) != 2 {
+		t.Fatalf("added %d records, want 2", len(added))
+	}
+	for _, record := range added {
+		switch record.Name {
+		case "www.example.com":
+			if record.ID != "record-2" || record.TTL != 60 {
+				t.Errorf("changed record has ID %s and TTL %d, want record-2 and 60", record.ID, record.TTL)
+			}
+		case "ftp.example.com":
+			if record.ID == "" || record.RData != "192.0.2.5" || record.ZoneID != "zone-example" {
+				t.Errorf("new record is %+v", record)
+			}
+		default:
+			t.Errorf("unexpected record %s added", record.Name)
+		}
+	}
+}
+
+// updateTestWriter stands in for the connection an update arrived on.
+type updateTestWriter struct {
+	addr net.Addr
+}
+
+func (w *updateTestWriter) RemoteAddr() net.Addr { return w.addr }
+
+func (w *updateTestWriter) Network() string { return "udp" }
+
+func (w *updateTestWriter) WriteResponse(response *odintypes.DNSRequest, maxSize int) error {
+	return nil
+}
+
+func TestApplyUpdate(t *testing.T) {
+	zoneSection := []odintypes.DNSQuestion{{Name: "example.com", Type: odintypes.TYPE_SOA, Class: odintypes.CLASS_IN}}
+	addFTP := []*odintypes.DNSRecord{updateRecord(t, "ftp.example.com", odintypes.TYPE_A, odintypes.CLASS_IN, 300, "192.0.2.5")}
+
+	tests := []struct {
+		name    string
+		client  string
+		request odintypes.DNSRequest
+		want    uint8
+		// changed tells whether the update reaches ApplyZoneChange and bumps the serial
+		changed bool
+	}{
+		{
+			name:    "add a record",
+			request: odintypes.DNSRequest{Questions: zoneSection, Authority: addFTP},
+			want:    odintypes.RCODE_NOERROR,
+			changed: true,
+		},
+		{
+			name:    "no zone section",
+			request: odintypes.DNSRequest{Authority: addFTP},
+			want:    odintypes.RCODE_FORMERR,
+		},
+		{
+			name:    "two zones",
+			request: odintypes.DNSRequest{Questions: append(slices.Clone(zoneSection), zoneSection...), Authority: addFTP},
+			want:    odintypes.RCODE_FORMERR,
+		},
+		{
+			name:    "zone section of type A",
+			request: odintypes.DNSRequest{Questions: []odintypes.DNSQuestion{{Name: "example.com", Type: odintypes.TYPE_A, Class: odintypes.CLASS_IN}}, Authority: addFTP},
+			want:    odintypes.RCODE_FORMERR,
+		},
+		{
+			name:    "name outside our zones",
+			request: odintypes.DNSRequest{Questions: []odintypes.DNSQuestion{{Name: "example.net", Type: odintypes.TYPE_SOA, Class: odintypes.CLASS_IN}}, Authority: addFTP},
+			want:    odintypes.RCODE_NOTAUTH,
+		},
+		{
+			name:    "name below the zone apex",
+			request: odintypes.DNSRequest{Questions: []odintypes.DNSQuestion{{Name: "www.example.com", Type: odintypes.TYPE_SOA, Class: odintypes.CLASS_IN}}, Authority: addFTP},
+			want:    odintypes.RCODE_NOTAUTH,
+		},
+		{
+			name:    "secondary zone",
+			request: odintypes.DNSRequest{Questions: []odintypes.DNSQuestion{{Name: "example.org", Type: odintypes.TYPE_SOA, Class: odintypes.CLASS_IN}}},
+			want:    odintypes.RCODE_REFUSED,
+		},
+		{
+			name:    "client not on the update ACL",
+			client:  "198.51.100.1",
+			request: odintypes.DNSRequest{Questions: zoneSection, Authority: addFTP},
+			want:    odintypes.RCODE_REFUSED,
+		},
+		{
+			name: "failed prerequisite",
+			request: odintypes.DNSRequest{
+				Questions: zoneSection,
+				Answers:   []*odintypes.DNSRecord{updateRecord(t, "ftp.example.com", odintypes.TYPE_ANY, odintypes.CLASS_ANY, 0, "")},
+				Authority: addFTP,
+			},
+			want: odintypes.RCODE_NXDOMAIN,
+		},
+		{
+			name: "update without effect",
+			request: odintypes.DNSRequest{
+				Questions: zoneSection,
+				Authority: []*odintypes.DNSRecord{updateRecord(t, "www.example.com", odintypes.TYPE_A, odintypes.CLASS_IN, 300, "192.0.2.1")},
+			},
+			want: odintypes.RCODE_NOERROR,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server, store := newUpdateTestServer(t)
+			clients, err := util.ParseClientNets([]string{"192.0.2.53"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			server.updateClients = clients
+
+			client := test.client
+			if client == "" {
+				client = "192.0.2.53"
+			}
+			w := &updateTestWriter{addr: &net.UDPAddr{IP: net.ParseIP(client), Port: 5353}}
+
+			rcode, err := server.applyUpdate(w, &test.request)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if rcode != test.want {
+				t.Errorf("RCODE %d, want %d", rcode, test.want)
+			}
+
+			changes := 0
+			if test.changed {
+				changes = 1
+			}
+			if store.changes != changes {
+				t.Errorf("ApplyZoneChange called %d times, want %d", store.changes, changes)
+			}
+			soa, err := store.GetZoneSOA("zone-example")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if bumped := soa.RData != "ns1.example.com hostmaster.example.com 10 3600 900 604800 300"; bumped != test.changed {
+				t.Errorf("SOA is %q after the update", soa.RData)
+			}
+		})
+	}
+}
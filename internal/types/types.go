@@ -2,6 +2,7 @@ package types
 
 import (
 	"database/sql"
+	"strings"
 	"time"
 )
 
@@ -30,15 +31,42 @@ type Session struct {
 	DeletedAt sql.NullTime `json:"deleted_at" db:"deleted_at"`
 }
 
+const (
+	ZoneKindPrimary   = "primary"
+	ZoneKindSecondary = "secondary"
+)
+
 type DBZone struct {
 	ID        string       `json:"id" db:"id"`
 	Owner     string       `json:"owner" db:"owner"`
 	Name      string       `json:"name" db:"name"`
+	Kind      string       `json:"kind" db:"kind"`
+	Primaries string       `json:"primaries" db:"primaries"`
+	ExpiresAt sql.NullTime `json:"expires_at" db:"expires_at"`
 	CreatedAt time.Time    `json:"created_at" db:"created_at"`
 	UpdatedAt time.Time    `json:"updated_at" db:"updated_at"`
 	DeletedAt sql.NullTime `json:"deleted_at" db:"deleted_at"`
 }
 
+// IsSecondary reports whether the zone is transferred from primaries elsewhere.
+func (z *DBZone) IsSecondary() bool {
+	return z.Kind == ZoneKindSecondary
+}
+
+// PrimaryAddresses returns the addresses a secondary zone is transferred from.
+func (z *DBZone) PrimaryAddresses() []string {
+	if z.Primaries == "" {
+		return nil
+	}
+	return strings.Split(z.Primaries, ",")
+}
+
+// Expired reports whether a secondary zone has not been refreshed within the
+// expire interval of its SOA, or was never transferred at all (RFC 1034 4.3.5).
+func (z *DBZone) Expired(now time.Time) bool {
+	return z.IsSecondary() && (!z.ExpiresAt.Valid || now.After(z.ExpiresAt.Time))
+}
+
 type DBRecord struct {
 	ID        string       `json:"id" db:"id"`
 	ZoneID    string       `json:"zone_id" db:"zone_id"`
@@ -59,3 +87,42 @@ type CacheRecord struct {
 	TTL   uint32 `json:"ttl"`
 	RData string `json:"rdata"`
 }
+
+// DBZoneTransferACL allows a secondary to transfer a zone. Network is an IP
+// address or a CIDR range.
+type DBZoneTransferACL struct {
+	ID        string    `json:"id" db:"id"`
+	ZoneID    string    `json:"zone_id" db:"zone_id"`
+	Network   string    `json:"network" db:"network"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+const (
+	JournalDelete = "delete"
+	JournalAdd    = "add"
+)
+
+// DBJournalEntry is one record deleted or added by the zone change that moved the
+// zone's SOA serial from SerialFrom to SerialTo.
+type DBJournalEntry struct {
+	ID         uint64    `json:"id" db:"id"`
+	ZoneID     string    `json:"zone_id" db:"zone_id"`
+	SerialFrom uint32    `json:"serial_from" db:"serial_from"`
+	SerialTo   uint32    `json:"serial_to" db:"serial_to"`
+	Operation  string    `json:"operation" db:"operation"`
+	Name       string    `json:"name" db:"name"`
+	Type       string    `json:"type" db:"type"`
+	Class      string    `json:"class" db:"class"`
+	TTL        uint32    `json:"ttl" db:"ttl"`
+	RData      string    `json:"rdata" db:"rdata"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+}
+
+// DBZoneSecondary is a secondary that gets a NOTIFY when its zone changes.
+// Address is an IP address and port.
+type DBZoneSecondary struct {
+	ID        string    `json:"id" db:"id"`
+	ZoneID    string    `json:"zone_id" db:"zone_id"`
+	Address   string    `json:"address" db:"address"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
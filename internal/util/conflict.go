@@ -0,0 +1,55 @@
+package util
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Unfield/Odin-DNS/internal/types"
+)
+
+// DNAMEConflict checks the constraints RFC 6672 2.3 and 2.4 put on DNAMEs
+// against the other entries of the zone and describes the first violation. A
+// DNAME owner can not have names below it, holds at most one DNAME and no CNAME.
+// A non-empty entryID skips that entry, it is the one being replaced.
+func DNAMEConflict(entries []types.DBRecord, entryID string, name string, recordType string) string {
+	for _, existing := range entries {
+		if entryID != "" && existing.ID == entryID {
+			continue
+		}
+
+		sameName := strings.EqualFold(existing.Name, name)
+		if existing.Type == "DNAME" && !sameName && IsSubdomain(name, existing.Name) {
+			return fmt.Sprintf("'%s' lies below the DNAME at '%s'", name, existing.Name)
+		}
+		if recordType == "DNAME" && !sameName && IsSubdomain(existing.Name, name) {
+			return fmt.Sprintf("a DNAME owner can not have names below it, found '%s'", existing.Name)
+		}
+		if sameName && recordType == "DNAME" && existing.Type == "DNAME" {
+			return fmt.Sprintf("'%s' already has a DNAME", name)
+		}
+		if sameName && (recordType == "DNAME" && existing.Type == "CNAME" || recordType == "CNAME" && existing.Type == "DNAME") {
+			return fmt.Sprintf("'%s' can not hold both a CNAME and a DNAME", name)
+		}
+	}
+	return ""
+}
+
+// AliasConflict checks that a name holds at most one ALIAS and that an ALIAS
+// does not share its name with a CNAME or with the A and AAAA records it stands in for.
+func AliasConflict(entries []types.DBRecord, entryID string, name string, recordType string) string {
+	for _, existing := range entries {
+		if entryID != "" && existing.ID == entryID || !strings.EqualFold(existing.Name, name) {
+			continue
+		}
+
+		if recordType == "ALIAS" && existing.Type == "ALIAS" {
+			return fmt.Sprintf("'%s' already has an ALIAS", name)
+		}
+		for _, pair := range [][2]string{{recordType, existing.Type}, {existing.Type, recordType}} {
+			if pair[0] == "ALIAS" && (pair[1] == "CNAME" || pair[1] == "A" || pair[1] == "AAAA") {
+				return fmt.Sprintf("'%s' can not hold both an ALIAS and a %s", name, pair[1])
+			}
+		}
+	}
+	return ""
+}
@@ -78,12 +78,40 @@ func ParseType(typeCode uint16) (string, error) {
 		return "SOA", nil
 	case 12:
 		return "PTR", nil
+	case 13:
+		return "HINFO", nil
 	case 15:
 		return "MX", nil
 	case 16:
 		return "TXT", nil
 	case 28:
 		return "AAAA", nil
+	case 29:
+		return "LOC", nil
+	case 33:
+		return "SRV", nil
+	case 35:
+		return "NAPTR", nil
+	case 39:
+		return "DNAME", nil
+	case 44:
+		return "SSHFP", nil
+	case 52:
+		return "TLSA", nil
+	case 53:
+		return "SMIMEA", nil
+	case 64:
+		return "SVCB", nil
+	case 65:
+		return "HTTPS", nil
+	case 251:
+		return "IXFR", nil
+	case 252:
+		return "AXFR", nil
+	case 257:
+		return "CAA", nil
+	case 65401:
+		return "ALIAS", nil
 	default:
 		return "", fmt.Errorf("unknown type code: %d", typeCode)
 	}
@@ -159,6 +187,25 @@ func splitDomainName(name string) []string {
 	return labels
 }
 
+// ParentDomain strips the leftmost label, the parent of a top level domain is the root "".
+func ParentDomain(name string) string {
+	_, parent, found := strings.Cut(name, ".")
+	if !found {
+		return ""
+	}
+	return parent
+}
+
+// IsSubdomain reports whether name equals parent or lies below it, ignoring case.
+func IsSubdomain(name string, parent string) bool {
+	name = strings.ToLower(strings.TrimSuffix(name, "."))
+	parent = strings.ToLower(strings.TrimSuffix(parent, "."))
+	if parent == "" || name == parent {
+		return true
+	}
+	return strings.HasSuffix(name, "."+parent)
+}
+
 type CheckForDemoFailedResponse struct {
 	Message string `json:"message"`
 }
@@ -185,14 +232,31 @@ func ConvertRDataStringToBytes(recordType uint16, rDataString string) ([]byte, e
 		return odintypes.ParseA_RData(rDataString)
 	case odintypes.TYPE_AAAA:
 		return odintypes.ParseAAAA_RData(rDataString)
-	case odintypes.TYPE_CNAME, odintypes.TYPE_NS, odintypes.TYPE_PTR:
+	case odintypes.TYPE_CNAME, odintypes.TYPE_NS, odintypes.TYPE_PTR, odintypes.TYPE_DNAME, odintypes.TYPE_ALIAS:
 		return odintypes.ParseDomainName_RData(rDataString)
 	case odintypes.TYPE_MX:
 		return odintypes.ParseMX_RData(rDataString)
+	case odintypes.TYPE_SRV:
+		return odintypes.ParseSRV_RData(rDataString)
 	case odintypes.TYPE_TXT:
 		return odintypes.ParseTXT_RData(rDataString)
+	case odintypes.TYPE_SOA:
+		return odintypes.ParseSOA_RData(rDataString)
+	case odintypes.TYPE_CAA:
+		return odintypes.ParseCAA_RData(rDataString)
+	case odintypes.TYPE_TLSA, odintypes.TYPE_SMIMEA:
+		return odintypes.ParseTLSA_RData(rDataString)
+	case odintypes.TYPE_SSHFP:
+		return odintypes.ParseSSHFP_RData(rDataString)
+	case odintypes.TYPE_NAPTR:
+		return odintypes.ParseNAPTR_RData(rDataString)
+	case odintypes.TYPE_LOC:
+		return odintypes.ParseLOC_RData(rDataString)
+	case odintypes.TYPE_SVCB, odintypes.TYPE_HTTPS:
+		return odintypes.ParseSVCB_RData(rDataString)
 	default:
-		return nil, fmt.Errorf("unsupported RData conversion for record type %d", recordType)
+		// types without a dedicated syntax are stored in the RFC 3597 generic format
+		return odintypes.ParseGeneric_RData(rDataString)
 	}
 }
 
@@ -202,14 +266,30 @@ func ConvertRDataBytesToString(recordType uint16, rDataBytes []byte) string {
 		return odintypes.FormatA_RData(rDataBytes)
 	case odintypes.TYPE_AAAA:
 		return odintypes.FormatAAAA_RData(rDataBytes)
-	case odintypes.TYPE_CNAME, odintypes.TYPE_NS, odintypes.TYPE_PTR:
+	case odintypes.TYPE_CNAME, odintypes.TYPE_NS, odintypes.TYPE_PTR, odintypes.TYPE_DNAME, odintypes.TYPE_ALIAS:
 		return odintypes.FormatDomainName_RData(rDataBytes)
 	case odintypes.TYPE_MX:
 		return odintypes.FormatMX_RData(rDataBytes)
+	case odintypes.TYPE_SRV:
+		return odintypes.FormatSRV_RData(rDataBytes)
 	case odintypes.TYPE_TXT:
 		return odintypes.FormatTXT_RData(rDataBytes)
+	case odintypes.TYPE_SOA:
+		return odintypes.FormatSOA_RData(rDataBytes)
+	case odintypes.TYPE_CAA:
+		return odintypes.FormatCAA_RData(rDataBytes)
+	case odintypes.TYPE_TLSA, odintypes.TYPE_SMIMEA:
+		return odintypes.FormatTLSA_RData(rDataBytes)
+	case odintypes.TYPE_SSHFP:
+		return odintypes.FormatSSHFP_RData(rDataBytes)
+	case odintypes.TYPE_NAPTR:
+		return odintypes.FormatNAPTR_RData(rDataBytes)
+	case odintypes.TYPE_LOC:
+		return odintypes.FormatLOC_RData(rDataBytes)
+	case odintypes.TYPE_SVCB, odintypes.TYPE_HTTPS:
+		return odintypes.FormatSVCB_RData(rDataBytes)
 	default:
-		return fmt.Sprintf("Unsupported_RData_Format_%d", recordType)
+		return odintypes.FormatGeneric_RData(rDataBytes)
 	}
 }
 
@@ -229,3 +309,20 @@ func ConvertMXRData(rdata string) (uint16, string, error) {
 	}
 	return uint16(prio), splitted[1], nil
 }
+
+func ConvertSRVRData(rdata string) (uint16, uint16, uint16, string, error) {
+	fields := strings.Fields(rdata)
+	if len(fields) != 4 {
+		return 0, 0, 0, "", fmt.Errorf("invalid SRV rdata: %s", rdata)
+	}
+
+	var numbers [3]uint16
+	for i := range numbers {
+		value, err := strconv.ParseUint(fields[i], 10, 16)
+		if err != nil {
+			return 0, 0, 0, "", err
+		}
+		numbers[i] = uint16(value)
+	}
+	return numbers[0], numbers[1], numbers[2], fields[3], nil
+}
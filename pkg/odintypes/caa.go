@@ -0,0 +1,107 @@
+package odintypes
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	CAA_FLAG_CRITICAL uint8 = 0x80
+
+	CAA_TAG_ISSUE     = "issue"
+	CAA_TAG_ISSUEWILD = "issuewild"
+	CAA_TAG_IODEF     = "iodef"
+)
+
+// CAAData is the decoded RDATA of a CAA record (RFC 8659 4.1).
+type CAAData struct {
+	Flags uint8
+	Tag   string
+	Value string
+}
+
+// ParseCAAData reads the presentation format 'FLAGS TAG "VALUE"'.
+func ParseCAAData(s string) (*CAAData, error) {
+	fields, err := ParseCharacterStrings(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CAA record RData: %w", err)
+	}
+	if len(fields) != 3 {
+		return nil, fmt.Errorf("invalid CAA record RData format, expected 'FLAGS TAG \"VALUE\"': %s", s)
+	}
+
+	flags, err := strconv.ParseUint(fields[0], 10, 8)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CAA flags '%s': %w", fields[0], err)
+	}
+
+	caa := &CAAData{
+		Flags: uint8(flags),
+		Tag:   fields[1],
+		Value: fields[2],
+	}
+	if err := validateCAATag(caa.Tag); err != nil {
+		return nil, err
+	}
+	return caa, nil
+}
+
+func (caa *CAAData) String() string {
+	return fmt.Sprintf("%d %s %s", caa.Flags, caa.Tag, QuoteCharacterString([]byte(caa.Value)))
+}
+
+// Pack encodes the CAA as wire RDATA, the value takes up the rest of the RDATA.
+func (caa *CAAData) Pack() ([]byte, error) {
+	if err := validateCAATag(caa.Tag); err != nil {
+		return nil, err
+	}
+	rData := []byte{caa.Flags, byte(len(caa.Tag))}
+	rData = append(rData, caa.Tag...)
+	return append(rData, caa.Value...), nil
+}
+
+func UnpackCAAData(rDataBytes []byte) (*CAAData, error) {
+	if len(rDataBytes) < 2 {
+		return nil, fmt.Errorf("CAA RData too short: %d bytes", len(rDataBytes))
+	}
+	tagLength := int(rDataBytes[1])
+	if tagLength == 0 || 2+tagLength > len(rDataBytes) {
+		return nil, fmt.Errorf("invalid CAA tag length %d", tagLength)
+	}
+	return &CAAData{
+		Flags: rDataBytes[0],
+		Tag:   string(rDataBytes[2 : 2+tagLength]),
+		Value: string(rDataBytes[2+tagLength:]),
+	}, nil
+}
+
+// validateCAATag checks the syntax of a property tag, which RFC 8659 limits to
+// 1 to 15 ASCII letters and digits.
+func validateCAATag(tag string) error {
+	if len(tag) == 0 || len(tag) > 15 {
+		return fmt.Errorf("CAA tag must be 1 to 15 characters long: '%s'", tag)
+	}
+	if strings.IndexFunc(tag, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9')
+	}) != -1 {
+		return fmt.Errorf("CAA tag may only contain letters and digits: '%s'", tag)
+	}
+	return nil
+}
+
+func ParseCAA_RData(s string) ([]byte, error) {
+	caa, err := ParseCAAData(s)
+	if err != nil {
+		return nil, err
+	}
+	return caa.Pack()
+}
+
+func FormatCAA_RData(rDataBytes []byte) string {
+	caa, err := UnpackCAAData(rDataBytes)
+	if err != nil {
+		return ""
+	}
+	return caa.String()
+}
@@ -0,0 +1,106 @@
+package odintypes
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ParseCharacterStrings splits RData in presentation format into its fields
+// (RFC 1035 5.1). Fields are separated by whitespace, may be enclosed in double
+// quotes to contain whitespace and support the \X and \DDD escapes.
+func ParseCharacterStrings(s string) ([]string, error) {
+	return parsePresentationFields(s, false)
+}
+
+// parsePresentationFields implements ParseCharacterStrings. With embeddedQuotes a
+// quoted part may also start within a field, as in the SvcParams 'key="a b"' of
+// RFC 9460 2.1, and the field continues after it.
+func parsePresentationFields(s string, embeddedQuotes bool) ([]string, error) {
+	var fields []string
+	i := 0
+	for {
+		for i < len(s) && isPresentationSpace(s[i]) {
+			i++
+		}
+		if i >= len(s) {
+			return fields, nil
+		}
+
+		quoted := s[i] == '"'
+		if quoted {
+			i++
+		}
+
+		var field strings.Builder
+		for i < len(s) {
+			c := s[i]
+			if c == '"' && (quoted || embeddedQuotes) {
+				i++
+				quoted = !quoted
+				if !quoted && !embeddedQuotes {
+					break
+				}
+				continue
+			}
+			if !quoted && isPresentationSpace(c) {
+				break
+			}
+			if c != '\\' {
+				field.WriteByte(c)
+				i++
+				continue
+			}
+
+			if i+1 >= len(s) {
+				return nil, fmt.Errorf("dangling escape at the end of '%s'", s)
+			}
+			if isDigit(s[i+1]) {
+				if i+3 >= len(s) || !isDigit(s[i+2]) || !isDigit(s[i+3]) {
+					return nil, fmt.Errorf("incomplete \\DDD escape in '%s'", s)
+				}
+				value := int(s[i+1]-'0')*100 + int(s[i+2]-'0')*10 + int(s[i+3]-'0')
+				if value > 255 {
+					return nil, fmt.Errorf("escape \\%s out of range in '%s'", s[i+1:i+4], s)
+				}
+				field.WriteByte(byte(value))
+				i += 4
+				continue
+			}
+			field.WriteByte(s[i+1])
+			i += 2
+		}
+
+		if quoted {
+			return nil, fmt.Errorf("unterminated quoted string in '%s'", s)
+		}
+		fields = append(fields, field.String())
+	}
+}
+
+// QuoteCharacterString renders b as a quoted presentation string, escaping quotes,
+// backslashes and non printable bytes so ParseCharacterStrings reads it back.
+func QuoteCharacterString(b []byte) string {
+	var quoted strings.Builder
+	quoted.WriteByte('"')
+	for _, c := range b {
+		switch {
+		case c == '"' || c == '\\':
+			quoted.WriteByte('\\')
+			quoted.WriteByte(c)
+		case c < 0x20 || c > 0x7E:
+			fmt.Fprintf(&quoted, "\\%03d", c)
+		default:
+			quoted.WriteByte(c)
+		}
+	}
+	quoted.WriteByte('"')
+	return quoted.String()
+}
+
+func isPresentationSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
@@ -0,0 +1,209 @@
+package odintypes
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// TLSAData is the decoded RDATA of a TLSA record (RFC 6698 2.1), SMIMEA records
+// (RFC 8162) share the same layout.
+type TLSAData struct {
+	Usage        uint8
+	Selector     uint8
+	MatchingType uint8
+	Data         []byte
+}
+
+// ParseTLSAData reads the presentation format 'USAGE SELECTOR MATCHING-TYPE HEX'.
+// The hex data may be split into several whitespace separated chunks.
+func ParseTLSAData(s string) (*TLSAData, error) {
+	fields := strings.Fields(s)
+	if len(fields) < 4 {
+		return nil, fmt.Errorf("invalid TLSA record RData format, expected 'USAGE SELECTOR MATCHING-TYPE DATA': %s", s)
+	}
+
+	numbers, err := parseUint8Fields(fields[:3], []string{"usage", "selector", "matching type"})
+	if err != nil {
+		return nil, fmt.Errorf("invalid TLSA %w", err)
+	}
+
+	tlsa := &TLSAData{
+		Usage:        numbers[0],
+		Selector:     numbers[1],
+		MatchingType: numbers[2],
+	}
+	if tlsa.Usage > 3 {
+		return nil, fmt.Errorf("invalid TLSA usage %d, expected 0 to 3", tlsa.Usage)
+	}
+	if tlsa.Selector > 1 {
+		return nil, fmt.Errorf("invalid TLSA selector %d, expected 0 or 1", tlsa.Selector)
+	}
+	if tlsa.MatchingType > 2 {
+		return nil, fmt.Errorf("invalid TLSA matching type %d, expected 0 to 2", tlsa.MatchingType)
+	}
+
+	tlsa.Data, err = decodeHexFields(fields[3:])
+	if err != nil {
+		return nil, fmt.Errorf("invalid TLSA certificate association data: %w", err)
+	}
+	if err := checkDigestLength(tlsa.MatchingType, tlsa.Data); err != nil {
+		return nil, fmt.Errorf("invalid TLSA certificate association data: %w", err)
+	}
+	return tlsa, nil
+}
+
+func (tlsa *TLSAData) String() string {
+	return fmt.Sprintf("%d %d %d %s", tlsa.Usage, tlsa.Selector, tlsa.MatchingType, strings.ToUpper(hex.EncodeToString(tlsa.Data)))
+}
+
+func (tlsa *TLSAData) Pack() []byte {
+	return append([]byte{tlsa.Usage, tlsa.Selector, tlsa.MatchingType}, tlsa.Data...)
+}
+
+func UnpackTLSAData(rDataBytes []byte) (*TLSAData, error) {
+	if len(rDataBytes) < 4 {
+		return nil, fmt.Errorf("TLSA RData too short: %d bytes", len(rDataBytes))
+	}
+	return &TLSAData{
+		Usage:        rDataBytes[0],
+		Selector:     rDataBytes[1],
+		MatchingType: rDataBytes[2],
+		Data:         rDataBytes[3:],
+	}, nil
+}
+
+// SSHFPData is the decoded RDATA of an SSHFP record (RFC 4255 3.1).
+type SSHFPData struct {
+	Algorithm   uint8
+	FPType      uint8
+	Fingerprint []byte
+}
+
+// ParseSSHFPData reads the presentation format 'ALGORITHM FP-TYPE HEX'.
+func ParseSSHFPData(s string) (*SSHFPData, error) {
+	fields := strings.Fields(s)
+	if len(fields) < 3 {
+		return nil, fmt.Errorf("invalid SSHFP record RData format, expected 'ALGORITHM FP-TYPE FINGERPRINT': %s", s)
+	}
+
+	numbers, err := parseUint8Fields(fields[:2], []string{"algorithm", "fingerprint type"})
+	if err != nil {
+		return nil, fmt.Errorf("invalid SSHFP %w", err)
+	}
+
+	sshfp := &SSHFPData{
+		Algorithm: numbers[0],
+		FPType:    numbers[1],
+	}
+	// 1 RSA, 2 DSA, 3 ECDSA, 4 Ed25519, 6 Ed448 (RFC 4255, 6594, 7479, 8709)
+	switch sshfp.Algorithm {
+	case 1, 2, 3, 4, 6:
+	default:
+		return nil, fmt.Errorf("invalid SSHFP algorithm %d, expected 1, 2, 3, 4 or 6", sshfp.Algorithm)
+	}
+	// 1 SHA-1, 2 SHA-256 (RFC 4255, 6594)
+	var expectedLength int
+	switch sshfp.FPType {
+	case 1:
+		expectedLength = 20
+	case 2:
+		expectedLength = 32
+	default:
+		return nil, fmt.Errorf("invalid SSHFP fingerprint type %d, expected 1 or 2", sshfp.FPType)
+	}
+
+	sshfp.Fingerprint, err = decodeHexFields(fields[2:])
+	if err != nil {
+		return nil, fmt.Errorf("invalid SSHFP fingerprint: %w", err)
+	}
+	if len(sshfp.Fingerprint) != expectedLength {
+		return nil, fmt.Errorf("invalid SSHFP fingerprint, expected %d bytes for type %d, got %d", expectedLength, sshfp.FPType, len(sshfp.Fingerprint))
+	}
+	return sshfp, nil
+}
+
+func (sshfp *SSHFPData) String() string {
+	return fmt.Sprintf("%d %d %s", sshfp.Algorithm, sshfp.FPType, strings.ToUpper(hex.EncodeToString(sshfp.Fingerprint)))
+}
+
+func (sshfp *SSHFPData) Pack() []byte {
+	return append([]byte{sshfp.Algorithm, sshfp.FPType}, sshfp.Fingerprint...)
+}
+
+func UnpackSSHFPData(rDataBytes []byte) (*SSHFPData, error) {
+	if len(rDataBytes) < 3 {
+		return nil, fmt.Errorf("SSHFP RData too short: %d bytes", len(rDataBytes))
+	}
+	return &SSHFPData{
+		Algorithm:   rDataBytes[0],
+		FPType:      rDataBytes[1],
+		Fingerprint: rDataBytes[2:],
+	}, nil
+}
+
+func parseUint8Fields(fields []string, names []string) ([]uint8, error) {
+	numbers := make([]uint8, len(fields))
+	for i, field := range fields {
+		value, err := strconv.ParseUint(field, 10, 8)
+		if err != nil {
+			return nil, fmt.Errorf("%s '%s': %w", names[i], field, err)
+		}
+		numbers[i] = uint8(value)
+	}
+	return numbers, nil
+}
+
+func decodeHexFields(fields []string) ([]byte, error) {
+	data, err := hex.DecodeString(strings.Join(fields, ""))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("data cannot be empty")
+	}
+	return data, nil
+}
+
+// checkDigestLength verifies the data length of the SHA-256 (1) and SHA-512 (2)
+// matching types, full certificates or keys (0) can have any length.
+func checkDigestLength(matchingType uint8, data []byte) error {
+	expected := map[uint8]int{1: 32, 2: 64}[matchingType]
+	if expected != 0 && len(data) != expected {
+		return fmt.Errorf("expected %d bytes for matching type %d, got %d", expected, matchingType, len(data))
+	}
+	return nil
+}
+
+func ParseTLSA_RData(s string) ([]byte, error) {
+	tlsa, err := ParseTLSAData(s)
+	if err != nil {
+		return nil, err
+	}
+	return tlsa.Pack(), nil
+}
+
+func FormatTLSA_RData(rDataBytes []byte) string {
+	tlsa, err := UnpackTLSAData(rDataBytes)
+	if err != nil {
+		return ""
+	}
+	return tlsa.String()
+}
+
+func ParseSSHFP_RData(s string) ([]byte, error) {
+	sshfp, err := ParseSSHFPData(s)
+	if err != nil {
+		return nil, err
+	}
+	return sshfp.Pack(), nil
+}
+
+func FormatSSHFP_RData(rDataBytes []byte) string {
+	sshfp, err := UnpackSSHFPData(rDataBytes)
+	if err != nil {
+		return ""
+	}
+	return sshfp.String()
+}
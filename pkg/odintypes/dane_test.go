@@ -0,0 +1,68 @@
+package odintypes
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTLSARoundTrip(t *testing.T) {
+	sha256 := strings.Repeat("AB", 32)
+	sha512 := strings.Repeat("CD", 64)
+
+	tests := []roundTripTest{
+		{in: "3 1 1 " + sha256},
+		{in: "2 0 2 " + sha512},
+		{in: "0 0 0 308201A2"},
+		{in: "3 1 1 " + strings.ToLower(sha256[:32]) + " " + sha256[32:], want: "3 1 1 " + sha256},
+	}
+	for _, test := range tests {
+		t.Run(test.in, func(t *testing.T) {
+			checkRoundTrip(t, ParseTLSA_RData, FormatTLSA_RData, test)
+		})
+	}
+}
+
+func TestTLSAInvalid(t *testing.T) {
+	sha256 := strings.Repeat("AB", 32)
+
+	tests := []string{
+		"3 1 1",
+		"4 1 1 " + sha256,
+		"3 2 1 " + sha256,
+		"3 1 3 " + sha256,
+		"256 1 1 " + sha256,
+		"3 1 1 " + sha256[:62],
+		"3 1 2 " + sha256,
+		"3 1 1 " + sha256[:63],
+		"3 1 1 " + sha256[:62] + "XY",
+	}
+	for _, test := range tests {
+		t.Run(test, func(t *testing.T) {
+			if rData, err := ParseTLSA_RData(test); err == nil {
+				t.Errorf("parsed as %x, want an error", rData)
+			}
+		})
+	}
+
+	if got := FormatTLSA_RData([]byte{3, 1, 1}); got != "" {
+		t.Errorf("TLSA without data formatted as %q, want an empty string", got)
+	}
+}
+
+func TestSSHFPRoundTrip(t *testing.T) {
+	tests := []roundTripTest{
+		{in: "4 2 " + strings.Repeat("0F", 32)},
+		{in: "1 1 " + strings.Repeat("a0", 20), want: "1 1 " + strings.Repeat("A0", 20)},
+	}
+	for _, test := range tests {
+		t.Run(test.in, func(t *testing.T) {
+			checkRoundTrip(t, ParseSSHFP_RData, FormatSSHFP_RData, test)
+		})
+	}
+
+	for _, invalid := range []string{"5 2 " + strings.Repeat("0F", 32), "4 3 " + strings.Repeat("0F", 32), "4 2 " + strings.Repeat("0F", 20)} {
+		if rData, err := ParseSSHFP_RData(invalid); err == nil {
+			t.Errorf("%q parsed as %x, want an error", invalid, rData)
+		}
+	}
+}
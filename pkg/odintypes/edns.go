@@ -0,0 +1,151 @@
+package odintypes
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+const (
+	EDNS_OPTION_NSID          uint16 = 3
+	EDNS_OPTION_CLIENT_SUBNET uint16 = 8
+	EDNS_OPTION_COOKIE        uint16 = 10
+	EDNS_OPTION_PADDING       uint16 = 12
+
+	EDNS_VERSION uint8 = 0
+)
+
+// EDNS is the decoded form of the OPT pseudo-record (RFC 6891).
+// ExtendedRCode holds the upper eight bits of the RCODE, PackResponse derives it from Header.Flags.RCode.
+type EDNS struct {
+	UDPPayloadSize uint16
+	ExtendedRCode  uint8
+	Version        uint8
+	DO             bool
+	Options        []EDNSOption
+}
+
+// EDNSOption is a single option from the OPT RDATA. New options implement this
+// interface and get a case in ParseEDNSOption; everything else stays an EDNSGenericOption.
+type EDNSOption interface {
+	Code() uint16
+	Pack() []byte
+}
+
+type EDNSGenericOption struct {
+	OptionCode uint16
+	Data       []byte
+}
+
+func (o *EDNSGenericOption) Code() uint16 { return o.OptionCode }
+func (o *EDNSGenericOption) Pack() []byte { return o.Data }
+
+type EDNSNSIDOption struct {
+	NSID []byte
+}
+
+func (o *EDNSNSIDOption) Code() uint16 { return EDNS_OPTION_NSID }
+func (o *EDNSNSIDOption) Pack() []byte { return o.NSID }
+
+// EDNSCookieOption carries DNS cookies (RFC 7873). The server cookie is empty in queries from new clients.
+type EDNSCookieOption struct {
+	ClientCookie []byte
+	ServerCookie []byte
+}
+
+func (o *EDNSCookieOption) Code() uint16 { return EDNS_OPTION_COOKIE }
+func (o *EDNSCookieOption) Pack() []byte {
+	return append(append([]byte{}, o.ClientCookie...), o.ServerCookie...)
+}
+
+type EDNSPaddingOption struct {
+	Length uint16
+}
+
+func (o *EDNSPaddingOption) Code() uint16 { return EDNS_OPTION_PADDING }
+func (o *EDNSPaddingOption) Pack() []byte { return make([]byte, o.Length) }
+
+func ParseEDNSOption(code uint16, data []byte) (EDNSOption, error) {
+	switch code {
+	case EDNS_OPTION_NSID:
+		return &EDNSNSIDOption{NSID: data}, nil
+	case EDNS_OPTION_COOKIE:
+		if len(data) != 8 && (len(data) < 16 || len(data) > 40) {
+			return nil, fmt.Errorf("invalid COOKIE option length: %d", len(data))
+		}
+		return &EDNSCookieOption{ClientCookie: data[:8], ServerCookie: data[8:]}, nil
+	case EDNS_OPTION_PADDING:
+		return &EDNSPaddingOption{Length: uint16(len(data))}, nil
+	default:
+		return &EDNSGenericOption{OptionCode: code, Data: data}, nil
+	}
+}
+
+func ParseEDNSOptions(rData []byte) ([]EDNSOption, error) {
+	var options []EDNSOption
+	offset := 0
+	for offset < len(rData) {
+		if offset+4 > len(rData) {
+			return nil, fmt.Errorf("OPT RData too short for option header at offset %d", offset)
+		}
+		code := binary.BigEndian.Uint16(rData[offset : offset+2])
+		length := int(binary.BigEndian.Uint16(rData[offset+2 : offset+4]))
+		offset += 4
+		if offset+length > len(rData) {
+			return nil, fmt.Errorf("OPT RData too short for option %d with length %d", code, length)
+		}
+		option, err := ParseEDNSOption(code, rData[offset:offset+length])
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, option)
+		offset += length
+	}
+	return options, nil
+}
+
+func PackEDNSOptions(options []EDNSOption) []byte {
+	var rData []byte
+	for _, option := range options {
+		data := option.Pack()
+		rData = binary.BigEndian.AppendUint16(rData, option.Code())
+		rData = binary.BigEndian.AppendUint16(rData, uint16(len(data)))
+		rData = append(rData, data...)
+	}
+	return rData
+}
+
+// ToRecord encodes the EDNS data as an OPT pseudo-record. CLASS carries the payload size
+// and TTL carries the extended RCODE, version and DO flag.
+func (e *EDNS) ToRecord() *DNSRecord {
+	ttl := uint32(e.ExtendedRCode)<<24 | uint32(e.Version)<<16
+	if e.DO {
+		ttl |= 1 << 15
+	}
+	return &DNSRecord{
+		Name:  "",
+		Type:  TYPE_OPT,
+		Class: e.UDPPayloadSize,
+		TTL:   ttl,
+		RData: PackEDNSOptions(e.Options),
+	}
+}
+
+func EDNSFromRecord(record *DNSRecord) (*EDNS, error) {
+	if record.Type != TYPE_OPT {
+		return nil, fmt.Errorf("record of type %d is not an OPT record", record.Type)
+	}
+	if record.Name != "" {
+		return nil, fmt.Errorf("OPT record must be owned by the root domain, got '%s'", record.Name)
+	}
+	options, err := ParseEDNSOptions(record.RData)
+	if err != nil {
+		return nil, err
+	}
+	return &EDNS{
+		UDPPayloadSize: record.Class,
+		ExtendedRCode:  uint8(record.TTL >> 24),
+		Version:        uint8(record.TTL >> 16),
+		DO:             record.TTL&(1<<15) != 0,
+		Options:        options,
+	}, nil
+}
@@ -0,0 +1,39 @@
+package odintypes
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParseGeneric_RData reads the RFC 3597 5 generic presentation format
+// '\# LENGTH HEX' used for types without a dedicated syntax. The hex data may be
+// split into several whitespace separated chunks.
+func ParseGeneric_RData(s string) ([]byte, error) {
+	fields := strings.Fields(s)
+	if len(fields) < 2 || fields[0] != `\#` {
+		return nil, fmt.Errorf("invalid generic RData format, expected '\\# LENGTH HEX': %s", s)
+	}
+
+	length, err := strconv.ParseUint(fields[1], 10, 16)
+	if err != nil {
+		return nil, fmt.Errorf("invalid generic RData length '%s': %w", fields[1], err)
+	}
+
+	data, err := hex.DecodeString(strings.Join(fields[2:], ""))
+	if err != nil {
+		return nil, fmt.Errorf("invalid generic RData hex data: %w", err)
+	}
+	if len(data) != int(length) {
+		return nil, fmt.Errorf("generic RData length %d does not match %d bytes of data", length, len(data))
+	}
+	return data, nil
+}
+
+func FormatGeneric_RData(rDataBytes []byte) string {
+	if len(rDataBytes) == 0 {
+		return `\# 0`
+	}
+	return fmt.Sprintf(`\# %d %s`, len(rDataBytes), strings.ToUpper(hex.EncodeToString(rDataBytes)))
+}
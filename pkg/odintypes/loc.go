@@ -0,0 +1,230 @@
+package odintypes
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+const (
+	locEquator       = 1 << 31  // latitude and longitude are offset by 2^31 thousandths of an arc second
+	locAltitudeBase  = 10000000 // altitude is measured in centimeters from 100000m below the WGS 84 spheroid
+	locMaxPrecision  = 9000000000
+	locDefaultSize   = 100     // 1m
+	locDefaultHorizP = 1000000 // 10000m
+	locDefaultVertP  = 1000    // 10m
+)
+
+// LOCData is the decoded RDATA of a LOC record (RFC 1876 2). Latitude and
+// longitude are in thousandths of an arc second relative to the equator and
+// the prime meridian, altitude and the sizes in centimeters.
+type LOCData struct {
+	Size                uint64
+	HorizontalPrecision uint64
+	VerticalPrecision   uint64
+	Latitude            int64
+	Longitude           int64
+	Altitude            int64
+}
+
+// ParseLOCData reads the presentation format of RFC 1876 3:
+// 'D1 [M1 [S1]] N|S D2 [M2 [S2]] E|W ALT[m] [SIZE[m] [HP[m] [VP[m]]]]'.
+func ParseLOCData(s string) (*LOCData, error) {
+	fields := strings.Fields(s)
+
+	latitude, fields, err := parseLOCCoordinate(fields, "N", "S", 90)
+	if err != nil {
+		return nil, fmt.Errorf("invalid LOC latitude: %w", err)
+	}
+	longitude, fields, err := parseLOCCoordinate(fields, "E", "W", 180)
+	if err != nil {
+		return nil, fmt.Errorf("invalid LOC longitude: %w", err)
+	}
+
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("invalid LOC record RData, altitude missing: %s", s)
+	}
+	if len(fields) > 4 {
+		return nil, fmt.Errorf("invalid LOC record RData, unexpected trailing fields: %s", s)
+	}
+
+	altitude, err := parseLOCMeters(fields[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid LOC altitude: %w", err)
+	}
+	if altitude < -locAltitudeBase || altitude > math.MaxUint32-locAltitudeBase {
+		return nil, fmt.Errorf("LOC altitude %s out of range", fields[0])
+	}
+
+	loc := &LOCData{
+		Size:                locDefaultSize,
+		HorizontalPrecision: locDefaultHorizP,
+		VerticalPrecision:   locDefaultVertP,
+		Latitude:            latitude,
+		Longitude:           longitude,
+		Altitude:            altitude,
+	}
+	for i, target := range []*uint64{&loc.Size, &loc.HorizontalPrecision, &loc.VerticalPrecision} {
+		if i+1 >= len(fields) {
+			break
+		}
+		value, err := parseLOCMeters(fields[i+1])
+		if err != nil || value < 0 || value > locMaxPrecision {
+			return nil, fmt.Errorf("invalid LOC size or precision '%s'", fields[i+1])
+		}
+		*target = uint64(value)
+	}
+	return loc, nil
+}
+
+// parseLOCCoordinate consumes degrees, optional minutes and seconds and the
+// hemisphere letter from fields.
+func parseLOCCoordinate(fields []string, positive string, negative string, maxDegrees int64) (int64, []string, error) {
+	var parts []string
+	for len(fields) > 0 && len(parts) < 4 {
+		field := fields[0]
+		fields = fields[1:]
+		if strings.EqualFold(field, positive) || strings.EqualFold(field, negative) {
+			if len(parts) == 0 {
+				return 0, nil, fmt.Errorf("degrees missing")
+			}
+
+			degrees, err := strconv.ParseInt(parts[0], 10, 64)
+			if err != nil || degrees < 0 || degrees > maxDegrees {
+				return 0, nil, fmt.Errorf("invalid degrees '%s'", parts[0])
+			}
+			var minutes int64
+			if len(parts) > 1 {
+				minutes, err = strconv.ParseInt(parts[1], 10, 64)
+				if err != nil || minutes < 0 || minutes > 59 {
+					return 0, nil, fmt.Errorf("invalid minutes '%s'", parts[1])
+				}
+			}
+			var seconds float64
+			if len(parts) > 2 {
+				seconds, err = strconv.ParseFloat(parts[2], 64)
+				if err != nil || seconds < 0 || seconds >= 60 {
+					return 0, nil, fmt.Errorf("invalid seconds '%s'", parts[2])
+				}
+			}
+
+			value := ((degrees*60+minutes)*60)*1000 + int64(math.Round(seconds*1000))
+			if value > maxDegrees*3600*1000 {
+				return 0, nil, fmt.Errorf("coordinate exceeds %d degrees", maxDegrees)
+			}
+			if strings.EqualFold(field, negative) {
+				value = -value
+			}
+			return value, fields, nil
+		}
+		parts = append(parts, field)
+	}
+	return 0, nil, fmt.Errorf("expected %s or %s", positive, negative)
+}
+
+// parseLOCMeters converts a distance like '12.5m' to centimeters.
+func parseLOCMeters(s string) (int64, error) {
+	value, err := strconv.ParseFloat(strings.TrimSuffix(strings.ToLower(s), "m"), 64)
+	if err != nil {
+		return 0, err
+	}
+	return int64(math.Round(value * 100)), nil
+}
+
+// packLOCPrecision encodes centimeters as the 4 bit mantissa and power of ten
+// exponent used for size and precision fields.
+func packLOCPrecision(centimeters uint64) byte {
+	var exponent byte
+	for centimeters >= 10 && exponent < 9 {
+		centimeters /= 10
+		exponent++
+	}
+	return byte(min(centimeters, 9))<<4 | exponent
+}
+
+func unpackLOCPrecision(value byte) (uint64, error) {
+	mantissa, exponent := uint64(value>>4), int(value&0x0F)
+	if mantissa > 9 || exponent > 9 {
+		return 0, fmt.Errorf("invalid LOC precision byte 0x%02x", value)
+	}
+	return mantissa * uint64(math.Pow10(exponent)), nil
+}
+
+func formatLOCCoordinate(value int64, positive string, negative string) string {
+	hemisphere := positive
+	if value < 0 {
+		hemisphere = negative
+		value = -value
+	}
+	degrees := value / 3600000
+	minutes := value / 60000 % 60
+	seconds := float64(value%60000) / 1000
+	return fmt.Sprintf("%d %d %.3f %s", degrees, minutes, seconds, hemisphere)
+}
+
+func formatLOCMeters(centimeters int64) string {
+	if centimeters%100 == 0 {
+		return fmt.Sprintf("%dm", centimeters/100)
+	}
+	return fmt.Sprintf("%.2fm", float64(centimeters)/100)
+}
+
+func (loc *LOCData) String() string {
+	return fmt.Sprintf("%s %s %s %s %s %s",
+		formatLOCCoordinate(loc.Latitude, "N", "S"),
+		formatLOCCoordinate(loc.Longitude, "E", "W"),
+		formatLOCMeters(loc.Altitude),
+		formatLOCMeters(int64(loc.Size)),
+		formatLOCMeters(int64(loc.HorizontalPrecision)),
+		formatLOCMeters(int64(loc.VerticalPrecision)))
+}
+
+// Pack encodes the LOC as version 0 wire RDATA. Sizes that can not be expressed
+// exactly by the mantissa and exponent encoding are rounded down.
+func (loc *LOCData) Pack() []byte {
+	rData := []byte{0, packLOCPrecision(loc.Size), packLOCPrecision(loc.HorizontalPrecision), packLOCPrecision(loc.VerticalPrecision)}
+	rData = binary.BigEndian.AppendUint32(rData, uint32(locEquator+loc.Latitude))
+	rData = binary.BigEndian.AppendUint32(rData, uint32(locEquator+loc.Longitude))
+	return binary.BigEndian.AppendUint32(rData, uint32(loc.Altitude+locAltitudeBase))
+}
+
+func UnpackLOCData(rDataBytes []byte) (*LOCData, error) {
+	if len(rDataBytes) != 16 {
+		return nil, fmt.Errorf("LOC RData must be 16 bytes, got %d", len(rDataBytes))
+	}
+	if rDataBytes[0] != 0 {
+		return nil, fmt.Errorf("unsupported LOC version %d", rDataBytes[0])
+	}
+
+	loc := &LOCData{
+		Latitude:  int64(binary.BigEndian.Uint32(rDataBytes[4:8])) - locEquator,
+		Longitude: int64(binary.BigEndian.Uint32(rDataBytes[8:12])) - locEquator,
+		Altitude:  int64(binary.BigEndian.Uint32(rDataBytes[12:16])) - locAltitudeBase,
+	}
+	for i, target := range []*uint64{&loc.Size, &loc.HorizontalPrecision, &loc.VerticalPrecision} {
+		value, err := unpackLOCPrecision(rDataBytes[1+i])
+		if err != nil {
+			return nil, err
+		}
+		*target = value
+	}
+	return loc, nil
+}
+
+func ParseLOC_RData(s string) ([]byte, error) {
+	loc, err := ParseLOCData(s)
+	if err != nil {
+		return nil, err
+	}
+	return loc.Pack(), nil
+}
+
+func FormatLOC_RData(rDataBytes []byte) string {
+	loc, err := UnpackLOCData(rDataBytes)
+	if err != nil {
+		return ""
+	}
+	return loc.String()
+}
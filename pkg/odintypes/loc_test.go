@@ -0,0 +1,76 @@
+package odintypes
+
+import "testing"
+
+func TestLOCRoundTrip(t *testing.T) {
+	tests := []roundTripTest{
+		{in: "52 22 23.000 N 4 53 32.000 E -2m 1m 10000m 10m"},
+		{in: "52 22 23 N 4 53 32 E -2m", want: "52 22 23.000 N 4 53 32.000 E -2m 1m 10000m 10m"},
+		{in: "42 21 54 N 71 06 18 W -24m 30m", want: "42 21 54.000 N 71 6 18.000 W -24m 30m 10000m 10m"},
+		{in: "33 52 S 151 12 30.5 E 10.5m 2m 100m 5m", want: "33 52 0.000 S 151 12 30.500 E 10.50m 2m 100m 5m"},
+		{in: "90 S 180 W 0m 0m 0m 0m", want: "90 0 0.000 S 180 0 0.000 W 0m 0m 0m 0m"},
+		{in: "0 N 0 E -100000m", want: "0 0 0.000 N 0 0 0.000 E -100000m 1m 10000m 10m"},
+		{in: "0 N 0 E 42849672.95m 90000000m 90000000m 90000000m", want: "0 0 0.000 N 0 0 0.000 E 42849672.95m 90000000m 90000000m 90000000m"},
+		// sizes the mantissa and exponent encoding can not hold are rounded down
+		{in: "0 N 0 E 0m 12345m 0.15m 2.5m", want: "0 0 0.000 N 0 0 0.000 E 0m 10000m 0.10m 2m"},
+	}
+	for _, test := range tests {
+		t.Run(test.in, func(t *testing.T) {
+			checkRoundTrip(t, ParseLOC_RData, FormatLOC_RData, test)
+		})
+	}
+}
+
+func TestLOCInvalid(t *testing.T) {
+	tests := []string{
+		"",
+		"52 22 23 N",
+		"52 22 23 N 4 53 32 E",
+		"N 4 53 32 E 0m",
+		"91 N 0 E 0m",
+		"90 0 0.001 N 0 E 0m",
+		"52 60 N 0 E 0m",
+		"52 22 60 N 0 E 0m",
+		"0 N 181 E 0m",
+		"0 N 0 X 0m",
+		"0 N 0 E -100000.01m",
+		"0 N 0 E 42849672.96m",
+		"0 N 0 E high",
+		"0 N 0 E 0m 90000000.01m",
+		"0 N 0 E 0m 1m 1m 90000001m",
+		"0 N 0 E 0m -1m",
+		"0 N 0 E 0m 1m 1m 1m 1m",
+	}
+	for _, test := range tests {
+		t.Run(test, func(t *testing.T) {
+			if rData, err := ParseLOC_RData(test); err == nil {
+				t.Errorf("parsed as %x, want an error", rData)
+			}
+		})
+	}
+}
+
+func TestLOCInvalidWire(t *testing.T) {
+	valid, err := ParseLOC_RData("52 22 23 N 4 53 32 E -2m")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	version := append([]byte{1}, valid[1:]...)
+	mantissa := append([]byte{0, 0xA0}, valid[2:]...)
+	exponent := append([]byte{0, 0x1A}, valid[2:]...)
+	tests := map[string][]byte{
+		"too short":             valid[:15],
+		"too long":              append(valid, 0),
+		"unknown version":       version,
+		"mantissa out of range": mantissa,
+		"exponent out of range": exponent,
+	}
+	for name, rData := range tests {
+		t.Run(name, func(t *testing.T) {
+			if loc, err := UnpackLOCData(rData); err == nil {
+				t.Errorf("unpacked as %q, want an error", loc)
+			}
+		})
+	}
+}
@@ -0,0 +1,156 @@
+package odintypes
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// NAPTRData is the decoded RDATA of a NAPTR record (RFC 3403 4.1).
+type NAPTRData struct {
+	Order       uint16
+	Preference  uint16
+	Flags       string
+	Service     string
+	Regexp      string
+	Replacement string
+}
+
+// ParseNAPTRData reads the presentation format
+// 'ORDER PREFERENCE "FLAGS" "SERVICE" "REGEXP" REPLACEMENT'.
+func ParseNAPTRData(s string) (*NAPTRData, error) {
+	fields, err := ParseCharacterStrings(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid NAPTR record RData: %w", err)
+	}
+	if len(fields) != 6 {
+		return nil, fmt.Errorf("invalid NAPTR record RData format, expected 'ORDER PREFERENCE \"FLAGS\" \"SERVICE\" \"REGEXP\" REPLACEMENT': %s", s)
+	}
+
+	order, err := strconv.ParseUint(fields[0], 10, 16)
+	if err != nil {
+		return nil, fmt.Errorf("invalid NAPTR order '%s': %w", fields[0], err)
+	}
+	preference, err := strconv.ParseUint(fields[1], 10, 16)
+	if err != nil {
+		return nil, fmt.Errorf("invalid NAPTR preference '%s': %w", fields[1], err)
+	}
+
+	naptr := &NAPTRData{
+		Order:       uint16(order),
+		Preference:  uint16(preference),
+		Flags:       fields[2],
+		Service:     fields[3],
+		Regexp:      fields[4],
+		Replacement: strings.TrimSuffix(fields[5], "."),
+	}
+	if err := naptr.Validate(); err != nil {
+		return nil, err
+	}
+	return naptr, nil
+}
+
+// Validate checks the field syntax of RFC 3403 4.1 and that only one of regexp
+// and replacement is used.
+func (naptr *NAPTRData) Validate() error {
+	for _, c := range naptr.Flags {
+		if !(c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' || c >= '0' && c <= '9') {
+			return fmt.Errorf("NAPTR flags may only contain letters and digits: '%s'", naptr.Flags)
+		}
+	}
+	for _, c := range naptr.Service {
+		if !(c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '+' || c == ':' || c == '-' || c == '.') {
+			return fmt.Errorf("NAPTR service contains invalid character '%c'", c)
+		}
+	}
+	for _, field := range []string{naptr.Flags, naptr.Service, naptr.Regexp} {
+		if len(field) > 255 {
+			return fmt.Errorf("NAPTR character-string too long (max 255 bytes): %d bytes", len(field))
+		}
+	}
+	if naptr.Regexp != "" && naptr.Replacement != "" {
+		return fmt.Errorf("NAPTR records must use either a regexp or a replacement, the other has to be empty or '.'")
+	}
+	if _, err := PackUncompressedName(naptr.Replacement); err != nil {
+		return fmt.Errorf("invalid NAPTR replacement: %w", err)
+	}
+	return nil
+}
+
+func (naptr *NAPTRData) String() string {
+	replacement := naptr.Replacement
+	if replacement == "" {
+		replacement = "."
+	}
+	return fmt.Sprintf("%d %d %s %s %s %s", naptr.Order, naptr.Preference,
+		QuoteCharacterString([]byte(naptr.Flags)),
+		QuoteCharacterString([]byte(naptr.Service)),
+		QuoteCharacterString([]byte(naptr.Regexp)),
+		replacement)
+}
+
+// Pack encodes the NAPTR as wire RDATA, the replacement is never compressed.
+func (naptr *NAPTRData) Pack() ([]byte, error) {
+	if err := naptr.Validate(); err != nil {
+		return nil, err
+	}
+
+	rData := binary.BigEndian.AppendUint16(nil, naptr.Order)
+	rData = binary.BigEndian.AppendUint16(rData, naptr.Preference)
+	for _, field := range []string{naptr.Flags, naptr.Service, naptr.Regexp} {
+		rData = append(rData, byte(len(field)))
+		rData = append(rData, field...)
+	}
+	replacement, _ := PackUncompressedName(naptr.Replacement)
+	return append(rData, replacement...), nil
+}
+
+func UnpackNAPTRData(rDataBytes []byte) (*NAPTRData, error) {
+	if len(rDataBytes) < 4 {
+		return nil, fmt.Errorf("NAPTR RData too short: %d bytes", len(rDataBytes))
+	}
+
+	naptr := &NAPTRData{
+		Order:      binary.BigEndian.Uint16(rDataBytes[0:2]),
+		Preference: binary.BigEndian.Uint16(rDataBytes[2:4]),
+	}
+	offset := 4
+	for _, field := range []*string{&naptr.Flags, &naptr.Service, &naptr.Regexp} {
+		if offset >= len(rDataBytes) {
+			return nil, fmt.Errorf("NAPTR RData too short for character-string")
+		}
+		length := int(rDataBytes[offset])
+		if offset+1+length > len(rDataBytes) {
+			return nil, fmt.Errorf("NAPTR character-string exceeds RData")
+		}
+		*field = string(rDataBytes[offset+1 : offset+1+length])
+		offset += 1 + length
+	}
+
+	replacement, offset, err := UnpackUncompressedName(rDataBytes, offset)
+	if err != nil {
+		return nil, fmt.Errorf("invalid NAPTR replacement: %w", err)
+	}
+	if offset != len(rDataBytes) {
+		return nil, fmt.Errorf("NAPTR RData has %d trailing bytes", len(rDataBytes)-offset)
+	}
+	naptr.Replacement = replacement
+	return naptr, nil
+}
+
+func ParseNAPTR_RData(s string) ([]byte, error) {
+	naptr, err := ParseNAPTRData(s)
+	if err != nil {
+		return nil, err
+	}
+	return naptr.Pack()
+}
+
+func FormatNAPTR_RData(rDataBytes []byte) string {
+	naptr, err := UnpackNAPTRData(rDataBytes)
+	if err != nil {
+		return ""
+	}
+	return naptr.String()
+}
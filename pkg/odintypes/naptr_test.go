@@ -0,0 +1,70 @@
+package odintypes
+
+import "testing"
+
+func TestNAPTRRoundTrip(t *testing.T) {
+	tests := []roundTripTest{
+		{in: `100 10 "U" "E2U+sip" "!^.*$!sip:info@example.com!" .`},
+		{in: `100 10 "U" "E2U+sip" "!^.*$!sip:\"info\"@example.com!" .`},
+		{in: `100 10 "U" "E2U+sip" "!^(.*)$!sip:\\1@example.com!" .`},
+		{in: `100 10 "U" "E2U+sip" "!^.*$!sip:caf\195\169@example.com!" .`},
+		{in: `100 50 "S" "SIP+D2U" "" _sip._udp.example.com.`, want: `100 50 "S" "SIP+D2U" "" _sip._udp.example.com`},
+		{in: `10 0 "" "" "" replacement.example.com`},
+		{in: `65535 65535 S SIP+D2T "" _sip._tcp.example.com`, want: `65535 65535 "S" "SIP+D2T" "" _sip._tcp.example.com`},
+	}
+	for _, test := range tests {
+		t.Run(test.in, func(t *testing.T) {
+			checkRoundTrip(t, ParseNAPTR_RData, FormatNAPTR_RData, test)
+		})
+	}
+}
+
+func TestNAPTRRegexpEscapes(t *testing.T) {
+	naptr, err := ParseNAPTRData(`100 10 "U" "E2U+sip" "!^.*$!sip:\"info\"@ex\\ample.com!" .`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `!^.*$!sip:"info"@ex\ample.com!`; naptr.Regexp != want {
+		t.Errorf("regexp is %q, want %q", naptr.Regexp, want)
+	}
+}
+
+func TestNAPTRInvalid(t *testing.T) {
+	tests := []string{
+		`100 10 "U" "E2U+sip" "!^.*$!sip:info@example.com!"`,
+		`100 10 "U" "E2U+sip" "!^.*$!sip:info@example.com!" . extra`,
+		`70000 10 "U" "E2U+sip" "" .`,
+		`100 -1 "U" "E2U+sip" "" .`,
+		`100 10 "U!" "E2U+sip" "" .`,
+		`100 10 "U" "E2U sip" "" .`,
+		`100 10 "U" "E2U+sip" "!^.*$!sip:info@example.com!" replacement.example.com`,
+		`100 10 "U" "E2U+sip" "!^.*$!sip:\"info@example.com! .`,
+		`100 10 "U" "E2U+sip" "!^(.*)$!\1@example.com!" .`,
+		`100 10 "U" "E2U+sip" "!^.*$!\256!" .`,
+	}
+	for _, test := range tests {
+		t.Run(test, func(t *testing.T) {
+			if rData, err := ParseNAPTR_RData(test); err == nil {
+				t.Errorf("parsed as %x, want an error", rData)
+			}
+		})
+	}
+}
+
+func TestNAPTRInvalidWire(t *testing.T) {
+	tests := map[string][]byte{
+		"too short":                   {0, 100, 0},
+		"character-string missing":    {0, 100, 0, 10, 1, 'U'},
+		"character-string exceeds":    {0, 100, 0, 10, 1, 'U', 7, 'E', '2', 'U'},
+		"trailing bytes":              {0, 100, 0, 10, 0, 0, 0, 0, 0},
+		"unterminated replacement":    {0, 100, 0, 10, 0, 0, 0, 3, 'c', 'o', 'm'},
+		"replacement label too short": {0, 100, 0, 10, 0, 0, 0, 5, 'c'},
+	}
+	for name, rData := range tests {
+		t.Run(name, func(t *testing.T) {
+			if naptr, err := UnpackNAPTRData(rData); err == nil {
+				t.Errorf("unpacked as %q, want an error", naptr)
+			}
+		})
+	}
+}
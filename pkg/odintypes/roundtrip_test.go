@@ -0,0 +1,40 @@
+package odintypes
+
+import (
+	"bytes"
+	"testing"
+)
+
+type roundTripTest struct {
+	in string
+	// want is the presentation form read back from the wire, empty if it equals in
+	want string
+}
+
+// checkRoundTrip converts in to wire RDATA and back to presentation format, which
+// has to read as the same wire RDATA again.
+func checkRoundTrip(t *testing.T, parse func(string) ([]byte, error), format func([]byte) string, test roundTripTest) {
+	t.Helper()
+
+	want := test.want
+	if want == "" {
+		want = test.in
+	}
+
+	wire, err := parse(test.in)
+	if err != nil {
+		t.Fatalf("parse %q: %v", test.in, err)
+	}
+	got := format(wire)
+	if got != want {
+		t.Fatalf("%q reads back as %q, want %q", test.in, got, want)
+	}
+
+	again, err := parse(got)
+	if err != nil {
+		t.Fatalf("parse %q read back from the wire: %v", got, err)
+	}
+	if !bytes.Equal(again, wire) {
+		t.Errorf("%q packs to %x, %q to %x", test.in, wire, got, again)
+	}
+}
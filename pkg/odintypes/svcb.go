@@ -0,0 +1,422 @@
+package odintypes
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// SvcParamKeys registered by RFC 9460 14.3.2.
+const (
+	SVCB_KEY_MANDATORY       uint16 = 0
+	SVCB_KEY_ALPN            uint16 = 1
+	SVCB_KEY_NO_DEFAULT_ALPN uint16 = 2
+	SVCB_KEY_PORT            uint16 = 3
+	SVCB_KEY_IPV4HINT        uint16 = 4
+	SVCB_KEY_ECH             uint16 = 5
+	SVCB_KEY_IPV6HINT        uint16 = 6
+)
+
+var svcbKeyNames = map[uint16]string{
+	SVCB_KEY_MANDATORY:       "mandatory",
+	SVCB_KEY_ALPN:            "alpn",
+	SVCB_KEY_NO_DEFAULT_ALPN: "no-default-alpn",
+	SVCB_KEY_PORT:            "port",
+	SVCB_KEY_IPV4HINT:        "ipv4hint",
+	SVCB_KEY_ECH:             "ech",
+	SVCB_KEY_IPV6HINT:        "ipv6hint",
+}
+
+// SVCBParam is a single SvcParam, Value holds the wire encoding of the value.
+type SVCBParam struct {
+	Key   uint16
+	Value []byte
+}
+
+// SVCBData is the decoded RDATA of an SVCB or HTTPS record (RFC 9460 2.2).
+// A priority of 0 marks the record as AliasMode.
+type SVCBData struct {
+	Priority uint16
+	Target   string
+	Params   []SVCBParam
+}
+
+// ParseSVCBData reads the presentation format 'PRIORITY TARGET [KEY=VALUE ...]'.
+// Values may be quoted and use the character-string escapes of RFC 1035 5.1.
+func ParseSVCBData(s string) (*SVCBData, error) {
+	fields, err := parsePresentationFields(s, true)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SVCB record RData: %w", err)
+	}
+	if len(fields) < 2 {
+		return nil, fmt.Errorf("invalid SVCB record RData format, expected 'PRIORITY TARGET [KEY=VALUE ...]': %s", s)
+	}
+
+	priority, err := strconv.ParseUint(fields[0], 10, 16)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SVCB priority '%s': %w", fields[0], err)
+	}
+
+	svcb := &SVCBData{
+		Priority: uint16(priority),
+		Target:   strings.TrimSuffix(fields[1], "."),
+	}
+
+	for _, field := range fields[2:] {
+		name, value, hasValue := strings.Cut(field, "=")
+		key, err := svcbKeyFromString(name)
+		if err != nil {
+			return nil, err
+		}
+		if hasValue && value == "" && key != SVCB_KEY_NO_DEFAULT_ALPN {
+			return nil, fmt.Errorf("SvcParam '%s' has an empty value", name)
+		}
+
+		packed, err := packSVCBParamValue(key, value, hasValue)
+		if err != nil {
+			return nil, err
+		}
+		svcb.Params = append(svcb.Params, SVCBParam{Key: key, Value: packed})
+	}
+
+	slices.SortFunc(svcb.Params, func(a, b SVCBParam) int {
+		return int(a.Key) - int(b.Key)
+	})
+	if err := svcb.Validate(); err != nil {
+		return nil, err
+	}
+	return svcb, nil
+}
+
+// Validate checks the constraints RFC 9460 puts on the set of SvcParams.
+// Params must already be sorted by key.
+func (svcb *SVCBData) Validate() error {
+	if _, err := PackUncompressedName(svcb.Target); err != nil {
+		return fmt.Errorf("invalid SVCB target: %w", err)
+	}
+	if svcb.Priority == 0 && len(svcb.Params) > 0 {
+		return fmt.Errorf("SVCB records in AliasMode (priority 0) must not have SvcParams")
+	}
+
+	present := map[uint16]bool{}
+	for i, param := range svcb.Params {
+		if i > 0 && svcb.Params[i-1].Key >= param.Key {
+			return fmt.Errorf("SvcParam '%s' is given more than once", svcbKeyToString(param.Key))
+		}
+		present[param.Key] = true
+	}
+
+	if present[SVCB_KEY_NO_DEFAULT_ALPN] && !present[SVCB_KEY_ALPN] {
+		return fmt.Errorf("SvcParam 'no-default-alpn' requires 'alpn'")
+	}
+
+	for _, param := range svcb.Params {
+		if param.Key != SVCB_KEY_MANDATORY {
+			continue
+		}
+		for i := 0; i+1 < len(param.Value); i += 2 {
+			key := binary.BigEndian.Uint16(param.Value[i:])
+			if key == SVCB_KEY_MANDATORY {
+				return fmt.Errorf("SvcParam 'mandatory' must not list itself")
+			}
+			if !present[key] {
+				return fmt.Errorf("mandatory SvcParam '%s' is missing", svcbKeyToString(key))
+			}
+		}
+	}
+	return nil
+}
+
+func (svcb *SVCBData) String() string {
+	target := svcb.Target
+	if target == "" {
+		target = "."
+	}
+
+	parts := []string{strconv.FormatUint(uint64(svcb.Priority), 10), target}
+	for _, param := range svcb.Params {
+		name := svcbKeyToString(param.Key)
+		if param.Key == SVCB_KEY_NO_DEFAULT_ALPN || len(param.Value) == 0 {
+			parts = append(parts, name)
+			continue
+		}
+		parts = append(parts, name+"="+formatSVCBParamValue(param))
+	}
+	return strings.Join(parts, " ")
+}
+
+// Pack encodes the SVCB as wire RDATA, the target name is never compressed.
+func (svcb *SVCBData) Pack() ([]byte, error) {
+	target, err := PackUncompressedName(svcb.Target)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SVCB target: %w", err)
+	}
+
+	rData := binary.BigEndian.AppendUint16(nil, svcb.Priority)
+	rData = append(rData, target...)
+	for _, param := range svcb.Params {
+		if len(param.Value) > 0xFFFF {
+			return nil, fmt.Errorf("SvcParam '%s' value too long", svcbKeyToString(param.Key))
+		}
+		rData = binary.BigEndian.AppendUint16(rData, param.Key)
+		rData = binary.BigEndian.AppendUint16(rData, uint16(len(param.Value)))
+		rData = append(rData, param.Value...)
+	}
+	return rData, nil
+}
+
+func UnpackSVCBData(rDataBytes []byte) (*SVCBData, error) {
+	if len(rDataBytes) < 3 {
+		return nil, fmt.Errorf("SVCB RData too short: %d bytes", len(rDataBytes))
+	}
+
+	target, offset, err := UnpackUncompressedName(rDataBytes, 2)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SVCB target: %w", err)
+	}
+
+	svcb := &SVCBData{
+		Priority: binary.BigEndian.Uint16(rDataBytes[0:2]),
+		Target:   target,
+	}
+	for offset < len(rDataBytes) {
+		if offset+4 > len(rDataBytes) {
+			return nil, fmt.Errorf("SVCB RData too short for SvcParam header")
+		}
+		key := binary.BigEndian.Uint16(rDataBytes[offset:])
+		length := int(binary.BigEndian.Uint16(rDataBytes[offset+2:]))
+		offset += 4
+		if offset+length > len(rDataBytes) {
+			return nil, fmt.Errorf("SvcParam '%s' value exceeds RData", svcbKeyToString(key))
+		}
+		param := SVCBParam{Key: key, Value: rDataBytes[offset : offset+length]}
+		if err := checkSVCBParamLength(param); err != nil {
+			return nil, err
+		}
+		svcb.Params = append(svcb.Params, param)
+		offset += length
+	}
+
+	if err := svcb.Validate(); err != nil {
+		return nil, err
+	}
+	return svcb, nil
+}
+
+// checkSVCBParamLength rejects wire values of the known keys that do not have the
+// size their format in RFC 9460 7 requires.
+func checkSVCBParamLength(param SVCBParam) error {
+	length := len(param.Value)
+	valid := true
+	switch param.Key {
+	case SVCB_KEY_MANDATORY:
+		valid = length > 0 && length%2 == 0
+	case SVCB_KEY_ALPN:
+		valid = length > 0
+		for i := 0; valid && i < length; i += 1 + int(param.Value[i]) {
+			valid = param.Value[i] > 0 && i+1+int(param.Value[i]) <= length
+		}
+	case SVCB_KEY_NO_DEFAULT_ALPN:
+		valid = length == 0
+	case SVCB_KEY_PORT:
+		valid = length == 2
+	case SVCB_KEY_IPV4HINT:
+		valid = length > 0 && length%net.IPv4len == 0
+	case SVCB_KEY_IPV6HINT:
+		valid = length > 0 && length%net.IPv6len == 0
+	case SVCB_KEY_ECH:
+		valid = length > 0
+	}
+	if !valid {
+		return fmt.Errorf("SvcParam '%s' has an invalid value length of %d bytes", svcbKeyToString(param.Key), length)
+	}
+	return nil
+}
+
+func svcbKeyFromString(name string) (uint16, error) {
+	for key, keyName := range svcbKeyNames {
+		if keyName == name {
+			return key, nil
+		}
+	}
+	if number, found := strings.CutPrefix(name, "key"); found {
+		key, err := strconv.ParseUint(number, 10, 16)
+		if err == nil && key != 65535 {
+			return uint16(key), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown SvcParam key '%s'", name)
+}
+
+func svcbKeyToString(key uint16) string {
+	if name, ok := svcbKeyNames[key]; ok {
+		return name
+	}
+	return fmt.Sprintf("key%d", key)
+}
+
+// packSVCBParamValue converts the presentation value of a SvcParam to its wire
+// format (RFC 9460 7). Unknown keys take the value as opaque bytes.
+func packSVCBParamValue(key uint16, value string, hasValue bool) ([]byte, error) {
+	name := svcbKeyToString(key)
+	if key == SVCB_KEY_NO_DEFAULT_ALPN {
+		if hasValue {
+			return nil, fmt.Errorf("SvcParam 'no-default-alpn' takes no value")
+		}
+		return []byte{}, nil
+	}
+	if !hasValue {
+		if _, known := svcbKeyNames[key]; known {
+			return nil, fmt.Errorf("SvcParam '%s' requires a value", name)
+		}
+		return []byte{}, nil
+	}
+
+	var packed []byte
+	switch key {
+	case SVCB_KEY_MANDATORY:
+		var keys []uint16
+		for _, item := range strings.Split(value, ",") {
+			mandatoryKey, err := svcbKeyFromString(item)
+			if err != nil {
+				return nil, err
+			}
+			keys = append(keys, mandatoryKey)
+		}
+		slices.Sort(keys)
+		for i, mandatoryKey := range keys {
+			if i > 0 && keys[i-1] == mandatoryKey {
+				return nil, fmt.Errorf("SvcParam 'mandatory' lists '%s' more than once", svcbKeyToString(mandatoryKey))
+			}
+			packed = binary.BigEndian.AppendUint16(packed, mandatoryKey)
+		}
+	case SVCB_KEY_ALPN:
+		protocols, err := splitSVCBValueList(value)
+		if err != nil {
+			return nil, err
+		}
+		for _, protocol := range protocols {
+			if len(protocol) == 0 || len(protocol) > 255 {
+				return nil, fmt.Errorf("invalid alpn protocol id '%s'", protocol)
+			}
+			packed = append(packed, byte(len(protocol)))
+			packed = append(packed, protocol...)
+		}
+	case SVCB_KEY_PORT:
+		port, err := strconv.ParseUint(value, 10, 16)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SvcParam port '%s': %w", value, err)
+		}
+		packed = binary.BigEndian.AppendUint16(packed, uint16(port))
+	case SVCB_KEY_IPV4HINT, SVCB_KEY_IPV6HINT:
+		for _, address := range strings.Split(value, ",") {
+			ip := net.ParseIP(address)
+			if key == SVCB_KEY_IPV4HINT {
+				if ip == nil || ip.To4() == nil {
+					return nil, fmt.Errorf("invalid ipv4hint address '%s'", address)
+				}
+				packed = append(packed, ip.To4()...)
+			} else {
+				if ip == nil || ip.To4() != nil {
+					return nil, fmt.Errorf("invalid ipv6hint address '%s'", address)
+				}
+				packed = append(packed, ip.To16()...)
+			}
+		}
+	case SVCB_KEY_ECH:
+		config, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SvcParam ech, expected base64: %w", err)
+		}
+		packed = config
+	default:
+		packed = []byte(value)
+	}
+	return packed, nil
+}
+
+func formatSVCBParamValue(param SVCBParam) string {
+	var items []string
+	switch param.Key {
+	case SVCB_KEY_MANDATORY:
+		for i := 0; i+1 < len(param.Value); i += 2 {
+			items = append(items, svcbKeyToString(binary.BigEndian.Uint16(param.Value[i:])))
+		}
+	case SVCB_KEY_ALPN:
+		for i := 0; i < len(param.Value); {
+			length := int(param.Value[i])
+			if i+1+length > len(param.Value) {
+				break
+			}
+			protocol := string(param.Value[i+1 : i+1+length])
+			protocol = strings.ReplaceAll(protocol, `\`, `\\`)
+			items = append(items, strings.ReplaceAll(protocol, ",", `\,`))
+			i += 1 + length
+		}
+		alpn := strings.Join(items, ",")
+		if strings.ContainsFunc(alpn, func(c rune) bool { return c <= ' ' || c > '~' || c == '"' || c == '\\' }) {
+			return QuoteCharacterString([]byte(alpn))
+		}
+		return alpn
+	case SVCB_KEY_PORT:
+		if len(param.Value) == 2 {
+			items = append(items, strconv.FormatUint(uint64(binary.BigEndian.Uint16(param.Value)), 10))
+		}
+	case SVCB_KEY_IPV4HINT:
+		for i := 0; i+net.IPv4len <= len(param.Value); i += net.IPv4len {
+			items = append(items, net.IP(param.Value[i:i+net.IPv4len]).String())
+		}
+	case SVCB_KEY_IPV6HINT:
+		for i := 0; i+net.IPv6len <= len(param.Value); i += net.IPv6len {
+			items = append(items, net.IP(param.Value[i:i+net.IPv6len]).String())
+		}
+	case SVCB_KEY_ECH:
+		items = append(items, base64.StdEncoding.EncodeToString(param.Value))
+	default:
+		return QuoteCharacterString(param.Value)
+	}
+	return strings.Join(items, ",")
+}
+
+// splitSVCBValueList splits a comma separated value-list, in which '\,' stands
+// for a comma and '\\' for a backslash within an item (RFC 9460 A.1).
+func splitSVCBValueList(value string) ([]string, error) {
+	var items []string
+	var item strings.Builder
+	for i := 0; i < len(value); i++ {
+		switch value[i] {
+		case ',':
+			items = append(items, item.String())
+			item.Reset()
+		case '\\':
+			if i+1 >= len(value) {
+				return nil, fmt.Errorf("dangling escape at the end of '%s'", value)
+			}
+			i++
+			item.WriteByte(value[i])
+		default:
+			item.WriteByte(value[i])
+		}
+	}
+	return append(items, item.String()), nil
+}
+
+func ParseSVCB_RData(s string) ([]byte, error) {
+	svcb, err := ParseSVCBData(s)
+	if err != nil {
+		return nil, err
+	}
+	return svcb.Pack()
+}
+
+func FormatSVCB_RData(rDataBytes []byte) string {
+	svcb, err := UnpackSVCBData(rDataBytes)
+	if err != nil {
+		return ""
+	}
+	return svcb.String()
+}
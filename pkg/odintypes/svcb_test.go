@@ -0,0 +1,121 @@
+package odintypes
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSVCBRoundTrip(t *testing.T) {
+	tests := []roundTripTest{
+		{in: "0 pool.svc.example.com"},
+		{in: "1 ."},
+		{in: "1 . alpn=h2,h3 port=8443 ipv4hint=192.0.2.1,192.0.2.2 ipv6hint=2001:db8::1"},
+		{in: "1 svc.example.com. mandatory=port,alpn alpn=h3 port=443", want: "1 svc.example.com mandatory=alpn,port alpn=h3 port=443"},
+		{in: "16 svc.example.com port=53 alpn=dot", want: "16 svc.example.com alpn=dot port=53"},
+		{in: "1 . alpn=h2 no-default-alpn"},
+		{in: "1 . ech=AEn+DQBFKwAgACABWIHUGj4u+PIggYXcR5JF0gYk3dCRioBW8uJq9H4mKAAIAAEAAQABAANAEnB1YmxpYy50bHMtZWNoLmRldgAA"},
+		{in: "1 . key667=hello", want: `1 . key667="hello"`},
+		{in: "1 . key667"},
+		{in: "1 . port=53 key65000=x", want: `1 . port=53 key65000="x"`},
+		{in: `1 . alpn="f\\\\oo\\,bar,h2"`},
+		{in: `1 . alpn=a\,b`, want: "1 . alpn=a,b"},
+		{in: `1 . key667="a b"`},
+		{in: `1 . key667="say \"hi\""`},
+		{in: `1 . key667="C:\\dns"`},
+		{in: `1 . key667="a\001"`},
+		{in: `1 . key667=a\001`, want: `1 . key667="a\001"`},
+		{in: `1 . "key667=a b"`, want: `1 . key667="a b"`},
+	}
+	for _, test := range tests {
+		t.Run(test.in, func(t *testing.T) {
+			checkRoundTrip(t, ParseSVCB_RData, FormatSVCB_RData, test)
+		})
+	}
+}
+
+func TestSVCBALPNEscapes(t *testing.T) {
+	// RFC 9460 D.2, Figure 7: the ids 'f\oo,bar' and 'h2'
+	rData := []byte{0, 16, 3, 'f', 'o', 'o', 7, 'e', 'x', 'a', 'm', 'p', 'l', 'e', 3, 'o', 'r', 'g', 0,
+		0, 1, 0, 12, 8, 'f', '\\', 'o', 'o', ',', 'b', 'a', 'r', 2, 'h', '2'}
+	want := `16 foo.example.org alpn="f\\\\oo\\,bar,h2"`
+
+	if got := FormatSVCB_RData(rData); got != want {
+		t.Fatalf("formatted as %q, want %q", got, want)
+	}
+	packed, err := ParseSVCB_RData(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(packed, rData) {
+		t.Errorf("%q packs to %x, want %x", want, packed, rData)
+	}
+}
+
+func TestSVCBInvalid(t *testing.T) {
+	tests := []string{
+		"1",
+		"70000 .",
+		"1 . foo=bar",
+		"1 . key65535=x",
+		"1 . keyabc=x",
+		"1 . port",
+		"1 . port=70000",
+		"1 . alpn=",
+		"1 . no-default-alpn=h2 alpn=h2",
+		"1 . no-default-alpn",
+		"1 . port=1 port=2",
+		"1 . mandatory=mandatory",
+		"1 . mandatory=alpn port=443",
+		"1 . mandatory=port,port port=443",
+		"1 . mandatory=foo port=443",
+		"0 . alpn=h2",
+		"1 . ipv4hint=2001:db8::1",
+		"1 . ipv6hint=192.0.2.1",
+		"1 . ech=!!!",
+		`1 . key667="a b`,
+		`1 . key667=a\`,
+		"1 . alpn=h2,",
+		`1 . alpn="h2\\"`,
+	}
+	for _, test := range tests {
+		t.Run(test, func(t *testing.T) {
+			if rData, err := ParseSVCB_RData(test); err == nil {
+				t.Errorf("parsed as %x, want an error", rData)
+			}
+		})
+	}
+}
+
+func TestSVCBInvalidWire(t *testing.T) {
+	tests := map[string][]byte{
+		"too short":            {0, 1},
+		"truncated key":        {0, 1, 0, 0, 3},
+		"value exceeds RDATA":  {0, 1, 0, 0, 3, 0, 5, 1},
+		"keys out of order":    {0, 1, 0, 0, 3, 0, 2, 1, 187, 0, 1, 0, 3, 2, 'h', '2'},
+		"params in AliasMode":  {0, 0, 0, 0, 3, 0, 2, 1, 187},
+		"unterminated target":  {0, 1, 3, 'c', 'o', 'm'},
+		"mandatory key absent": {0, 1, 0, 0, 0, 0, 2, 0, 3},
+		"empty mandatory":      {0, 1, 0, 0, 0, 0, 0},
+		"odd mandatory":        {0, 1, 0, 0, 0, 0, 3, 0, 3, 0, 0, 3, 0, 2, 1, 187},
+		"empty alpn":           {0, 1, 0, 0, 1, 0, 0},
+		"alpn trailing bytes":  {0, 1, 0, 0, 1, 0, 4, 2, 'h', '2', 3},
+		"empty alpn id":        {0, 1, 0, 0, 1, 0, 3, 0, 1, 'x'},
+		"no-default-alpn data": {0, 1, 0, 0, 1, 0, 3, 2, 'h', '2', 0, 2, 0, 1, 0},
+		"short port":           {0, 1, 0, 0, 3, 0, 1, 53},
+		"long port":            {0, 1, 0, 0, 3, 0, 3, 0, 53, 0},
+		"ipv4hint length":      {0, 1, 0, 0, 4, 0, 5, 192, 0, 2, 1, 2},
+		"empty ipv4hint":       {0, 1, 0, 0, 4, 0, 0},
+		"empty ech":            {0, 1, 0, 0, 5, 0, 0},
+		"ipv6hint length":      {0, 1, 0, 0, 6, 0, 4, 0x20, 0x01, 0x0d, 0xb8},
+	}
+	for name, rData := range tests {
+		t.Run(name, func(t *testing.T) {
+			if svcb, err := UnpackSVCBData(rData); err == nil {
+				t.Errorf("unpacked as %q, want an error", svcb)
+			}
+			if got := FormatSVCB_RData(rData); got != "" {
+				t.Errorf("formatted as %q, want an empty string", got)
+			}
+		})
+	}
+}
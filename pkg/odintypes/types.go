@@ -6,6 +6,7 @@ import (
 	"net"
 	"strconv"
 	"strings"
+	"time"
 )
 
 type DNSRequest struct {
@@ -14,6 +15,7 @@ type DNSRequest struct {
 	Answers    []*DNSRecord
 	Authority  []*DNSRecord
 	Additional []*DNSRecord
+	EDNS       *EDNS
 }
 
 type DNSHeader struct {
@@ -85,19 +87,57 @@ type DNSRecord struct {
 }
 
 const (
-	TYPE_A     uint16 = 1
-	TYPE_NS    uint16 = 2
-	TYPE_CNAME uint16 = 5
-	TYPE_SOA   uint16 = 6
-	TYPE_MX    uint16 = 15
-	TYPE_TXT   uint16 = 16
-	TYPE_AAAA  uint16 = 28
-	TYPE_SRV   uint16 = 33
-	TYPE_PTR   uint16 = 12
-	TYPE_ANY   uint16 = 255
+	TYPE_A      uint16 = 1
+	TYPE_NS     uint16 = 2
+	TYPE_CNAME  uint16 = 5
+	TYPE_SOA    uint16 = 6
+	TYPE_HINFO  uint16 = 13
+	TYPE_MX     uint16 = 15
+	TYPE_TXT    uint16 = 16
+	TYPE_AAAA   uint16 = 28
+	TYPE_LOC    uint16 = 29
+	TYPE_NAPTR  uint16 = 35
+	TYPE_DNAME  uint16 = 39
+	TYPE_SSHFP  uint16 = 44
+	TYPE_TLSA   uint16 = 52
+	TYPE_SMIMEA uint16 = 53
+	TYPE_SRV    uint16 = 33
+	TYPE_PTR    uint16 = 12
+	TYPE_OPT    uint16 = 41
+	TYPE_SVCB   uint16 = 64
+	TYPE_HTTPS  uint16 = 65
+	TYPE_IXFR   uint16 = 251
+	TYPE_AXFR   uint16 = 252
+	TYPE_ANY    uint16 = 255
+	TYPE_CAA    uint16 = 257
+	// TYPE_ALIAS is a private use type (RFC 6895 3.1) that is stored like a
+	// CNAME but answered with the A/AAAA records of its target, it never
+	// appears on the wire.
+	TYPE_ALIAS uint16 = 65401
 
 	CLASS_IN    uint16 = 1
 	CLASS_CHAOS uint16 = 3
+	// CLASS_NONE and CLASS_ANY only appear in the prerequisite and update
+	// sections of dynamic updates (RFC 2136 2.4, 2.5)
+	CLASS_NONE uint16 = 254
+	CLASS_ANY  uint16 = 255
+
+	OPCODE_QUERY  uint8 = 0
+	OPCODE_NOTIFY uint8 = 4
+	OPCODE_UPDATE uint8 = 5
+
+	RCODE_NOERROR  uint8 = 0
+	RCODE_FORMERR  uint8 = 1
+	RCODE_SERVFAIL uint8 = 2
+	RCODE_NXDOMAIN uint8 = 3
+	RCODE_NOTIMP   uint8 = 4
+	RCODE_REFUSED  uint8 = 5
+	RCODE_YXDOMAIN uint8 = 6
+	RCODE_YXRRSET  uint8 = 7
+	RCODE_NXRRSET  uint8 = 8
+	RCODE_NOTAUTH  uint8 = 9
+	RCODE_NOTZONE  uint8 = 10
+	RCODE_BADVERS  uint8 = 16
 )
 
 func StringToType(s string) (uint16, error) {
@@ -110,20 +150,49 @@ func StringToType(s string) (uint16, error) {
 		return TYPE_CNAME, nil
 	case "SOA":
 		return TYPE_SOA, nil
+	case "HINFO":
+		return TYPE_HINFO, nil
 	case "MX":
 		return TYPE_MX, nil
 	case "TXT":
 		return TYPE_TXT, nil
 	case "AAAA":
 		return TYPE_AAAA, nil
+	case "LOC":
+		return TYPE_LOC, nil
+	case "NAPTR":
+		return TYPE_NAPTR, nil
+	case "DNAME":
+		return TYPE_DNAME, nil
+	case "SSHFP":
+		return TYPE_SSHFP, nil
+	case "TLSA":
+		return TYPE_TLSA, nil
+	case "SMIMEA":
+		return TYPE_SMIMEA, nil
 	case "SRV":
 		return TYPE_SRV, nil
 	case "PTR":
 		return TYPE_PTR, nil
+	case "OPT":
+		return TYPE_OPT, nil
+	case "SVCB":
+		return TYPE_SVCB, nil
+	case "HTTPS":
+		return TYPE_HTTPS, nil
+	case "IXFR":
+		return TYPE_IXFR, nil
+	case "AXFR":
+		return TYPE_AXFR, nil
 	case "ANY":
 		return TYPE_ANY, nil
+	case "CAA":
+		return TYPE_CAA, nil
+	case "ALIAS":
+		return TYPE_ALIAS, nil
 	default:
-		if i, err := strconv.ParseUint(s, 10, 16); err == nil {
+		// numeric types may also use the RFC 3597 'TYPE1234' notation
+		if i, err := strconv.ParseUint(strings.TrimPrefix(s, "TYPE"), 10, 16); err == nil {
 			return uint16(i), nil
 		}
 		return 0, fmt.Errorf("unknown DNS record type: %s", s)
@@ -140,18 +209,46 @@ func TypeToString(t uint16) string {
 		return "CNAME"
 	case TYPE_SOA:
 		return "SOA"
+	case TYPE_HINFO:
+		return "HINFO"
 	case TYPE_MX:
 		return "MX"
 	case TYPE_TXT:
 		return "TXT"
 	case TYPE_AAAA:
 		return "AAAA"
+	case TYPE_LOC:
+		return "LOC"
+	case TYPE_NAPTR:
+		return "NAPTR"
+	case TYPE_DNAME:
+		return "DNAME"
+	case TYPE_SSHFP:
+		return "SSHFP"
+	case TYPE_TLSA:
+		return "TLSA"
+	case TYPE_SMIMEA:
+		return "SMIMEA"
 	case TYPE_SRV:
 		return "SRV"
 	case TYPE_PTR:
 		return "PTR"
+	case TYPE_OPT:
+		return "OPT"
+	case TYPE_SVCB:
+		return "SVCB"
+	case TYPE_HTTPS:
+		return "HTTPS"
+	case TYPE_IXFR:
+		return "IXFR"
+	case TYPE_AXFR:
+		return "AXFR"
 	case TYPE_ANY:
 		return "ANY"
+	case TYPE_CAA:
+		return "CAA"
+	case TYPE_ALIAS:
+		return "ALIAS"
 	default:
 		return fmt.Sprintf("TYPE%d", t)
 	}
@@ -238,11 +335,86 @@ func ParseMX_RData(s string) ([]byte, error) {
 	return append(prefBytes, []byte(domainName)...), nil
 }
 
+// ParseTXT_RData accepts one or more quoted character-strings ('"v=DKIM1; k=rsa" "p=..."').
+// A value that does not start with a quote is taken literally as a single text,
+// which keeps the plain values stored before quoting was supported working.
 func ParseTXT_RData(s string) ([]byte, error) {
-	if len(s) > 255 {
-		return nil, fmt.Errorf("TXT record string is too long (max 255 bytes): %d bytes", len(s))
+	if !strings.HasPrefix(strings.TrimLeft(s, " \t"), "\"") {
+		return PackTXTStrings([]string{s})
 	}
-	return []byte(s), nil
+
+	texts, err := ParseCharacterStrings(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid TXT record RData: %w", err)
+	}
+	return PackTXTStrings(texts)
+}
+
+// PackTXTStrings encodes texts as a sequence of character-strings, splitting every
+// text longer than 255 bytes into chunks (RFC 1035 3.3.14, RFC 7208 3.3).
+func PackTXTStrings(texts []string) ([]byte, error) {
+	if len(texts) == 0 {
+		texts = []string{""}
+	}
+
+	var rData []byte
+	for _, text := range texts {
+		for {
+			chunk := text[:min(len(text), 255)]
+			rData = append(rData, byte(len(chunk)))
+			rData = append(rData, chunk...)
+			text = text[len(chunk):]
+			if len(text) == 0 {
+				break
+			}
+		}
+	}
+	if len(rData) > 0xFFFF {
+		return nil, fmt.Errorf("TXT record RData is too long: %d bytes", len(rData))
+	}
+	return rData, nil
+}
+
+// UnpackTXTStrings splits TXT wire RData into its character-strings.
+func UnpackTXTStrings(rDataBytes []byte) ([]string, error) {
+	if len(rDataBytes) == 0 {
+		return nil, fmt.Errorf("TXT RData must contain at least one character-string")
+	}
+
+	var texts []string
+	for offset := 0; offset < len(rDataBytes); {
+		length := int(rDataBytes[offset])
+		if offset+1+length > len(rDataBytes) {
+			return nil, fmt.Errorf("TXT character-string exceeds RData")
+		}
+		texts = append(texts, string(rDataBytes[offset+1:offset+1+length]))
+		offset += 1 + length
+	}
+	return texts, nil
+}
+
+func ParseSRV_RData(s string) ([]byte, error) {
+	fields := strings.Fields(s)
+	if len(fields) != 4 {
+		return nil, fmt.Errorf("invalid SRV record RData format, expected 'PRIORITY WEIGHT PORT TARGET': %s", s)
+	}
+
+	rData := make([]byte, 0, 6+len(fields[3]))
+	for i, name := range []string{"priority", "weight", "port"} {
+		value, err := strconv.ParseUint(fields[i], 10, 16)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SRV %s '%s': %w", name, fields[i], err)
+		}
+		rData = binary.BigEndian.AppendUint16(rData, uint16(value))
+	}
+
+	// "." is a valid target and means the service is decidedly not available (RFC 2782)
+	target := fields[3]
+	if _, err := PackUncompressedName(target); err != nil {
+		return nil, fmt.Errorf("invalid SRV target: %w", err)
+	}
+
+	return append(rData, target...), nil
 }
 
 func FormatA_RData(rDataBytes []byte) string {
@@ -280,7 +452,16 @@ func FormatMX_RData(rDataBytes []byte) string {
 }
 
 func FormatTXT_RData(rDataBytes []byte) string {
-	return string(rDataBytes)
+	texts, err := UnpackTXTStrings(rDataBytes)
+	if err != nil {
+		return ""
+	}
+
+	quoted := make([]string, len(texts))
+	for i, text := range texts {
+		quoted[i] = QuoteCharacterString([]byte(text))
+	}
+	return strings.Join(quoted, " ")
 }
 
 func FormatSRV_RData(rDataBytes []byte) string {
@@ -300,3 +481,168 @@ func FormatSRV_RData(rDataBytes []byte) string {
 
 	return fmt.Sprintf("%d %d %d %s", priority, weight, port, targetString)
 }
+
+// SOAData is the decoded RDATA of an SOA record (RFC 1035 3.3.13).
+type SOAData struct {
+	MName   string
+	RName   string
+	Serial  uint32
+	Refresh uint32
+	Retry   uint32
+	Expire  uint32
+	Minimum uint32
+}
+
+func ParseSOAData(s string) (*SOAData, error) {
+	fields := strings.Fields(s)
+	if len(fields) != 7 {
+		return nil, fmt.Errorf("invalid SOA record RData format, expected 'MNAME RNAME SERIAL REFRESH RETRY EXPIRE MINIMUM': %s", s)
+	}
+
+	soa := &SOAData{
+		MName: strings.TrimSuffix(fields[0], "."),
+		RName: strings.TrimSuffix(fields[1], "."),
+	}
+	if soa.MName == "" || soa.RName == "" {
+		return nil, fmt.Errorf("SOA MNAME and RNAME cannot be empty")
+	}
+
+	numbers := []*uint32{&soa.Serial, &soa.Refresh, &soa.Retry, &soa.Expire, &soa.Minimum}
+	for i, target := range numbers {
+		value, err := strconv.ParseUint(fields[i+2], 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SOA value '%s': %w", fields[i+2], err)
+		}
+		*target = uint32(value)
+	}
+
+	return soa, nil
+}
+
+func (soa *SOAData) String() string {
+	return fmt.Sprintf("%s %s %d %d %d %d %d", soa.MName, soa.RName, soa.Serial, soa.Refresh, soa.Retry, soa.Expire, soa.Minimum)
+}
+
+// Pack encodes the SOA as wire RDATA with uncompressed names.
+func (soa *SOAData) Pack() ([]byte, error) {
+	mname, err := PackUncompressedName(soa.MName)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SOA MNAME: %w", err)
+	}
+	rname, err := PackUncompressedName(soa.RName)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SOA RNAME: %w", err)
+	}
+
+	rData := append(mname, rname...)
+	for _, value := range []uint32{soa.Serial, soa.Refresh, soa.Retry, soa.Expire, soa.Minimum} {
+		rData = binary.BigEndian.AppendUint32(rData, value)
+	}
+	return rData, nil
+}
+
+func UnpackSOAData(rDataBytes []byte) (*SOAData, error) {
+	mname, offset, err := UnpackUncompressedName(rDataBytes, 0)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SOA MNAME: %w", err)
+	}
+	rname, offset, err := UnpackUncompressedName(rDataBytes, offset)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SOA RNAME: %w", err)
+	}
+	if len(rDataBytes)-offset != 20 {
+		return nil, fmt.Errorf("SOA RData must end with 20 bytes of timers, got %d", len(rDataBytes)-offset)
+	}
+
+	fields := rDataBytes[offset:]
+	return &SOAData{
+		MName:   mname,
+		RName:   rname,
+		Serial:  binary.BigEndian.Uint32(fields[0:4]),
+		Refresh: binary.BigEndian.Uint32(fields[4:8]),
+		Retry:   binary.BigEndian.Uint32(fields[8:12]),
+		Expire:  binary.BigEndian.Uint32(fields[12:16]),
+		Minimum: binary.BigEndian.Uint32(fields[16:20]),
+	}, nil
+}
+
+// NextSOASerial returns the serial that follows current using the YYYYMMDDnn
+// convention. Serials that are already ahead of today's date are simply
+// incremented, wrapping around as allowed by RFC 1982.
+func NextSOASerial(current uint32, now time.Time) uint32 {
+	dateSerial := uint32(now.Year()*10000+int(now.Month())*100+now.Day()) * 100
+	if current < dateSerial {
+		return dateSerial
+	}
+	return current + 1
+}
+
+// SOASerialLess compares two serials in RFC 1982 serial number arithmetic, so a
+// serial that wrapped around still counts as newer.
+func SOASerialLess(a, b uint32) bool {
+	return a != b && int32(b-a) > 0
+}
+
+func ParseSOA_RData(s string) ([]byte, error) {
+	soa, err := ParseSOAData(s)
+	if err != nil {
+		return nil, err
+	}
+	return soa.Pack()
+}
+
+func FormatSOA_RData(rDataBytes []byte) string {
+	soa, err := UnpackSOAData(rDataBytes)
+	if err != nil {
+		return ""
+	}
+	return soa.String()
+}
+
+// PackUncompressedName encodes a domain name as a sequence of length prefixed labels.
+func PackUncompressedName(name string) ([]byte, error) {
+	name = strings.TrimSuffix(name, ".")
+	var packed []byte
+	if name != "" {
+		for _, label := range strings.Split(name, ".") {
+			if len(label) == 0 {
+				return nil, fmt.Errorf("domain name '%s' contains an empty label", name)
+			}
+			if len(label) > 63 {
+				return nil, fmt.Errorf("DNS label '%s' too long (max 63 characters)", label)
+			}
+			packed = append(packed, byte(len(label)))
+			packed = append(packed, label...)
+		}
+	}
+	packed = append(packed, 0x00)
+	if len(packed) > 255 {
+		return nil, fmt.Errorf("domain name '%s' too long (max 255 bytes)", name)
+	}
+	return packed, nil
+}
+
+// UnpackUncompressedName reads a name written by PackUncompressedName and returns
+// it without trailing dot together with the offset after its terminating zero byte.
+func UnpackUncompressedName(rDataBytes []byte, offset int) (string, int, error) {
+	var labels []string
+	for {
+		if offset >= len(rDataBytes) {
+			return "", offset, fmt.Errorf("RData too short for domain name")
+		}
+		length := int(rDataBytes[offset])
+		offset++
+		if length == 0 {
+			break
+		}
+		if length > 63 {
+			return "", offset, fmt.Errorf("invalid label length %d in uncompressed domain name", length)
+		}
+		if offset+length > len(rDataBytes) {
+			return "", offset, fmt.Errorf("RData too short for domain label")
+		}
+		labels = append(labels, string(rDataBytes[offset:offset+length]))
+		offset += length
+	}
+	return strings.Join(labels, "."), offset, nil
+}